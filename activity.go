@@ -0,0 +1,289 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewActivityCalendar counts a user's lessons and reviews by day and by hour of
+// the day, and finds their streaks of consecutive days with at least one
+// lesson or review.
+//
+// Lessons are counted at their assignment's StartedAt and reviews at their
+// CreatedAt. Days without activity that fall on a vacation don't break a
+// streak, and today doesn't break the current streak until it's over.
+func NewActivityCalendar(params *ActivityCalendarParams) (*ActivityCalendar, error) {
+	if params.Now.IsZero() {
+		return nil, fmt.Errorf("ActivityCalendarParams.Now must be set")
+	}
+	if params.DayStart < 0 || params.DayStart >= 24*time.Hour {
+		return nil, fmt.Errorf("ActivityCalendarParams.DayStart must be at least zero and less than 24 hours")
+	}
+
+	loc := params.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	vacations := append([]*ActivityPeriod(nil), params.Vacations...)
+	if params.User != nil && params.User.Data != nil && params.User.Data.CurrentVacationStartedAt != nil {
+		vacations = append(vacations,
+			&ActivityPeriod{End: params.Now, Start: *params.User.Data.CurrentVacationStartedAt})
+	}
+
+	// dayStartOn returns when the day labeled with the given date starts. It's
+	// built from the wall clock so that days stay aligned across changes to
+	// and from daylight saving time.
+	dayStartOn := func(date time.Time) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(),
+			int(params.DayStart/time.Hour), int(params.DayStart%time.Hour/time.Minute),
+			int(params.DayStart%time.Minute/time.Second), int(params.DayStart%time.Second), loc)
+	}
+
+	// dateOf returns the midnight of the date that a time's day is labeled
+	// with, accounting for the day boundary.
+	dateOf := func(t time.Time) time.Time {
+		local := t.In(loc)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		if local.Before(dayStartOn(date)) {
+			date = date.AddDate(0, 0, -1)
+		}
+		return date
+	}
+
+	calendar := &ActivityCalendar{}
+	counts := make(map[activityDate]*ActivityDay)
+
+	count := func(t time.Time, lesson bool) {
+		if t.After(params.Now) {
+			return
+		}
+
+		if lesson {
+			calendar.HourlyLessons[t.In(loc).Hour()]++
+		} else {
+			calendar.HourlyReviews[t.In(loc).Hour()]++
+		}
+
+		date := dateOf(t)
+		day, ok := counts[activityDateOf(date)]
+		if !ok {
+			day = &ActivityDay{Date: date}
+			counts[activityDateOf(date)] = day
+		}
+
+		if lesson {
+			day.NumLessons++
+		} else {
+			day.NumReviews++
+		}
+	}
+
+	for _, assignment := range params.Assignments {
+		if assignment.Data != nil && assignment.Data.StartedAt != nil {
+			count(*assignment.Data.StartedAt, true)
+		}
+	}
+	for _, review := range params.Reviews {
+		if review.Data != nil {
+			count(review.Data.CreatedAt, false)
+		}
+	}
+
+	if len(counts) == 0 {
+		return calendar, nil
+	}
+
+	var first time.Time
+	for _, day := range counts {
+		if first.IsZero() || day.Date.Before(first) {
+			first = day.Date
+		}
+	}
+
+	today := dateOf(params.Now)
+	for date := first; !date.After(today); date = date.AddDate(0, 0, 1) {
+		day, ok := counts[activityDateOf(date)]
+		if !ok {
+			day = &ActivityDay{Date: date}
+		}
+
+		dayStart := dayStartOn(date)
+		dayEnd := dayStartOn(date.AddDate(0, 0, 1))
+		for _, vacation := range vacations {
+			if vacation.Start.Before(dayEnd) && vacation.End.After(dayStart) {
+				day.Vacation = true
+				break
+			}
+		}
+
+		calendar.Days = append(calendar.Days, day)
+	}
+
+	var streak *ActivityStreak
+	for i, day := range calendar.Days {
+		isToday := i == len(calendar.Days)-1
+
+		switch {
+		case day.Active():
+			if streak == nil {
+				streak = &ActivityStreak{Start: day.Date}
+			}
+			streak.End = day.Date
+			streak.NumDays++
+
+			if calendar.LongestStreak == nil || streak.NumDays > calendar.LongestStreak.NumDays {
+				longest := *streak
+				calendar.LongestStreak = &longest
+			}
+
+		case day.Vacation || isToday:
+			// Doesn't break the streak.
+
+		default:
+			streak = nil
+		}
+	}
+	calendar.CurrentStreak = streak
+
+	return calendar, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// ActivityCalendar is a user's lessons and reviews by day and by hour of the
+// day, along with their streaks. See NewActivityCalendar.
+type ActivityCalendar struct {
+	// CurrentStreak is the streak that's still going. Nil if the user doesn't
+	// have one.
+	CurrentStreak *ActivityStreak
+
+	// Days are every day from the first one with activity through today, in
+	// order, for drawing a calendar heatmap. Empty if there's no activity.
+	Days []*ActivityDay
+
+	// HourlyLessons are the number of lessons done in each hour of the day in
+	// the calendar's location, indexed by hour.
+	HourlyLessons [24]int
+
+	// HourlyReviews are the number of reviews done in each hour of the day in
+	// the calendar's location, indexed by hour.
+	HourlyReviews [24]int
+
+	// LongestStreak is the longest streak, which may be CurrentStreak. The
+	// earliest is used if more than one are tied. Nil if the user has no
+	// activity.
+	LongestStreak *ActivityStreak
+}
+
+// ActivityCalendarParams are parameters for NewActivityCalendar.
+type ActivityCalendarParams struct {
+	// Assignments are the user's assignments, which are needed for when their
+	// lessons were done.
+	Assignments []*Assignment
+
+	// DayStart is the time after midnight that days start. For example, with
+	// four hours, activity at 2 AM counts toward the day before. Must be less
+	// than 24 hours.
+	DayStart time.Duration
+
+	// Location is the user's time zone, which determines the boundaries of
+	// days and hours. Defaults to UTC.
+	Location *time.Location
+
+	// Now is the current time. Activity after it is ignored.
+	Now time.Time
+
+	// Reviews are the user's reviews.
+	Reviews []*Review
+
+	// User is the user, whose current vacation is added to Vacations. Optional.
+	User *User
+
+	// Vacations are periods when the user was on vacation.
+	Vacations []*ActivityPeriod
+}
+
+// ActivityDay is a day in an ActivityCalendar.
+type ActivityDay struct {
+	// Date is midnight of the day's date in the calendar's location. The day
+	// itself runs from ActivityCalendarParams.DayStart after it.
+	Date time.Time
+
+	// NumLessons is the number of lessons done during the day.
+	NumLessons int
+
+	// NumReviews is the number of reviews done during the day.
+	NumReviews int
+
+	// Vacation is whether the user was on vacation for any of the day.
+	Vacation bool
+}
+
+// Active returns whether any lessons or reviews were done during the day.
+func (d *ActivityDay) Active() bool {
+	return d.NumLessons > 0 || d.NumReviews > 0
+}
+
+// ActivityPeriod is a period of time like a vacation.
+type ActivityPeriod struct {
+	// End is the end of the period.
+	End time.Time
+
+	// Start is the start of the period.
+	Start time.Time
+}
+
+// ActivityStreak is a run of days with activity. Days without activity that
+// fell on a vacation are part of the streak, but aren't counted in NumDays.
+type ActivityStreak struct {
+	// End is the date of the streak's last active day.
+	End time.Time
+
+	// NumDays is the number of active days in the streak.
+	NumDays int
+
+	// Start is the date of the streak's first active day.
+	Start time.Time
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// activityDate is a calendar date used to key days. Unlike a time.Time, it
+// compares equal regardless of location or monotonic clock reading.
+type activityDate struct {
+	y int
+	m time.Month
+	d int
+}
+
+func activityDateOf(t time.Time) activityDate {
+	y, m, d := t.Date()
+	return activityDate{y, m, d}
+}
@@ -0,0 +1,156 @@
+package wanikaniapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewActivityCalendar(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 3, day, hour, 0, 0, 0, jst)
+	}
+	date := func(day int) time.Time {
+		return at(day, 0)
+	}
+
+	review := func(createdAt time.Time) *wanikaniapi.Review {
+		return &wanikaniapi.Review{Data: &wanikaniapi.ReviewData{CreatedAt: createdAt.UTC()}}
+	}
+
+	params := &wanikaniapi.ActivityCalendarParams{
+		Assignments: []*wanikaniapi.Assignment{
+			{Data: &wanikaniapi.AssignmentData{StartedAt: timePtr(at(2, 5).UTC())}},
+			{Data: &wanikaniapi.AssignmentData{}},
+		},
+		DayStart: 4 * time.Hour,
+		Location: jst,
+		Now:      at(10, 12),
+		Reviews: []*wanikaniapi.Review{
+			review(at(1, 10)),
+
+			// Before the day boundary, so it counts toward the 1st.
+			review(at(2, 3)),
+
+			// Nothing on the 3rd breaks the streak.
+			review(at(4, 20)),
+			review(at(5, 20)),
+			review(at(6, 20)),
+
+			// Vacation on the 7th and 8th.
+			review(at(9, 20)),
+
+			// Nothing yet today, but the day isn't over.
+
+			// In the future.
+			review(at(11, 20)),
+		},
+		Vacations: []*wanikaniapi.ActivityPeriod{
+			{Start: at(7, 4), End: at(9, 4)},
+		},
+	}
+
+	t.Run("Vacations", func(t *testing.T) {
+		calendar, err := wanikaniapi.NewActivityCalendar(params)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 10, len(calendar.Days))
+		assert.Equal(t, &wanikaniapi.ActivityDay{Date: date(1), NumReviews: 2}, calendar.Days[0])
+		assert.Equal(t, &wanikaniapi.ActivityDay{Date: date(2), NumLessons: 1}, calendar.Days[1])
+		assert.Equal(t, &wanikaniapi.ActivityDay{Date: date(3)}, calendar.Days[2])
+		assert.Equal(t, &wanikaniapi.ActivityDay{Date: date(7), Vacation: true}, calendar.Days[6])
+		assert.Equal(t, &wanikaniapi.ActivityDay{Date: date(10)}, calendar.Days[9])
+
+		expected := &wanikaniapi.ActivityStreak{End: date(9), NumDays: 4, Start: date(4)}
+		assert.Equal(t, expected, calendar.CurrentStreak)
+		assert.Equal(t, expected, calendar.LongestStreak)
+
+		assert.Equal(t, 1, calendar.HourlyLessons[5])
+		assert.Equal(t, 1, calendar.HourlyReviews[3])
+		assert.Equal(t, 1, calendar.HourlyReviews[10])
+		assert.Equal(t, 4, calendar.HourlyReviews[20])
+	})
+
+	t.Run("CurrentVacation", func(t *testing.T) {
+		p := *params
+		p.User = &wanikaniapi.User{Data: &wanikaniapi.UserData{CurrentVacationStartedAt: timePtr(at(7, 12))}}
+		p.Vacations = nil
+
+		calendar, err := wanikaniapi.NewActivityCalendar(&p)
+		assert.NoError(t, err)
+
+		assert.True(t, calendar.Days[6].Vacation)
+		assert.True(t, calendar.Days[9].Vacation)
+		assert.Equal(t, 4, calendar.CurrentStreak.NumDays)
+	})
+
+	t.Run("NoVacations", func(t *testing.T) {
+		p := *params
+		p.Vacations = nil
+
+		calendar, err := wanikaniapi.NewActivityCalendar(&p)
+		assert.NoError(t, err)
+
+		assert.Equal(t, &wanikaniapi.ActivityStreak{End: date(9), NumDays: 1, Start: date(9)}, calendar.CurrentStreak)
+		assert.Equal(t, &wanikaniapi.ActivityStreak{End: date(6), NumDays: 3, Start: date(4)}, calendar.LongestStreak)
+	})
+
+	t.Run("DaylightSavingTime", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skipf("time zone database not available: %v", err)
+		}
+
+		// Clocks jump from 2 to 3 AM on March 14th, 2021, so the 14th is 23
+		// hours long.
+		at := func(day, hour, min int) time.Time {
+			return time.Date(2021, 3, day, hour, min, 0, 0, newYork)
+		}
+
+		calendar, err := wanikaniapi.NewActivityCalendar(&wanikaniapi.ActivityCalendarParams{
+			DayStart: 4 * time.Hour,
+			Location: newYork,
+			Now:      at(15, 12, 0),
+			Reviews: []*wanikaniapi.Review{
+				review(at(13, 3, 30)),
+				review(at(14, 4, 30)),
+			},
+			Vacations: []*wanikaniapi.ActivityPeriod{
+				{Start: at(14, 4, 15), End: at(14, 4, 45)},
+			},
+		})
+		assert.NoError(t, err)
+
+		date := func(day int) time.Time {
+			return at(day, 0, 0)
+		}
+		assert.Equal(t, []*wanikaniapi.ActivityDay{
+			{Date: date(12), NumReviews: 1},
+			{Date: date(13)},
+			{Date: date(14), NumReviews: 1, Vacation: true},
+			{Date: date(15)},
+		}, calendar.Days)
+	})
+
+	t.Run("NoActivity", func(t *testing.T) {
+		calendar, err := wanikaniapi.NewActivityCalendar(&wanikaniapi.ActivityCalendarParams{Now: at(10, 12)})
+		assert.NoError(t, err)
+		assert.Empty(t, calendar.Days)
+		assert.Nil(t, calendar.CurrentStreak)
+		assert.Nil(t, calendar.LongestStreak)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := wanikaniapi.NewActivityCalendar(&wanikaniapi.ActivityCalendarParams{})
+		assert.EqualError(t, err, "ActivityCalendarParams.Now must be set")
+
+		_, err = wanikaniapi.NewActivityCalendar(&wanikaniapi.ActivityCalendarParams{
+			DayStart: 24 * time.Hour,
+			Now:      at(10, 12),
+		})
+		assert.EqualError(t, err, "ActivityCalendarParams.DayStart must be at least zero and less than 24 hours")
+	})
+}
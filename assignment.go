@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -176,6 +177,16 @@ type AssignmentPage struct {
 	Data []*Assignment `json:"data"`
 }
 
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p AssignmentPage) MarshalJSON() ([]byte, error) {
+	type page AssignmentPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
+
 // AssignmentStartParams are parameters for AssignmentStart.
 type AssignmentStartParams struct {
 	Params
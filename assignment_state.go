@@ -0,0 +1,265 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewAssignmentState derives the lifecycle state of an assignment from its
+// SRS stage and timestamps, and the spaced repetition system of its subject.
+//
+// A nil assignment, like for a subject that the user hasn't unlocked yet,
+// produces a locked state. Returns an error if the spaced repetition system or
+// its data is nil, or if the assignment's SRS stage isn't a stage of the
+// system.
+func NewAssignmentState(assignment *Assignment, srs *SpacedRepetitionSystem) (*AssignmentState, error) {
+	if srs == nil || srs.Data == nil {
+		return nil, fmt.Errorf("spaced repetition system with data must be given")
+	}
+
+	if assignment == nil || assignment.Data == nil || assignment.Data.UnlockedAt == nil {
+		return &AssignmentState{
+			Group: AssignmentStageGroupLocked,
+			Stage: srs.Data.UnlockingStagePosition,
+		}, nil
+	}
+
+	return srs.Data.StageState(assignment.Data.SRSStage)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// AssignmentStageGroup is a named group of SRS stages like "apprentice" or
+// "guru", as shown in WaniKani's interface.
+type AssignmentStageGroup string
+
+// All possible assignment stage groups, in the order that an assignment
+// progresses through them.
+const (
+	AssignmentStageGroupLocked      AssignmentStageGroup = "locked"
+	AssignmentStageGroupLesson      AssignmentStageGroup = "lesson"
+	AssignmentStageGroupApprentice  AssignmentStageGroup = "apprentice"
+	AssignmentStageGroupGuru        AssignmentStageGroup = "guru"
+	AssignmentStageGroupMaster      AssignmentStageGroup = "master"
+	AssignmentStageGroupEnlightened AssignmentStageGroup = "enlightened"
+	AssignmentStageGroupBurned      AssignmentStageGroup = "burned"
+)
+
+// AssignmentStageGroups are all assignment stage groups in the order that an
+// assignment progresses through them.
+var AssignmentStageGroups = []AssignmentStageGroup{
+	AssignmentStageGroupLocked,
+	AssignmentStageGroupLesson,
+	AssignmentStageGroupApprentice,
+	AssignmentStageGroupGuru,
+	AssignmentStageGroupMaster,
+	AssignmentStageGroupEnlightened,
+	AssignmentStageGroupBurned,
+}
+
+// Passed returns true for stage groups at or beyond the passing stage.
+func (g AssignmentStageGroup) Passed() bool {
+	switch g {
+	case AssignmentStageGroupGuru, AssignmentStageGroupMaster,
+		AssignmentStageGroupEnlightened, AssignmentStageGroupBurned:
+		return true
+	}
+	return false
+}
+
+// String returns the group's name as it's displayed like "Apprentice".
+func (g AssignmentStageGroup) String() string {
+	if g == "" {
+		return ""
+	}
+	return strings.ToUpper(string(g[:1])) + string(g[1:])
+}
+
+// AssignmentState is the lifecycle state of an assignment like "Apprentice 3"
+// or "Burned".
+type AssignmentState struct {
+	// Group is the stage group that the assignment is in.
+	Group AssignmentStageGroup
+
+	// GroupPosition is the one-based position of the stage within its group,
+	// like 3 for "Apprentice 3".
+	GroupPosition int
+
+	// GroupSize is the number of stages in the group, like 4 for apprentice
+	// in WaniKani's usual systems.
+	GroupSize int
+
+	// Stage is the position of the assignment's SRS stage. For locked
+	// assignments, it's the system's unlocking stage position.
+	Stage int
+}
+
+// String returns the state as it's displayed like "Apprentice 3". The
+// position is omitted for groups with only one stage.
+func (s *AssignmentState) String() string {
+	if s.GroupSize <= 1 {
+		return s.Group.String()
+	}
+	return fmt.Sprintf("%s %v", s.Group, s.GroupPosition)
+}
+
+// AssignmentTransition is a kind of change between two assignment states.
+type AssignmentTransition string
+
+// All possible kinds of assignment transitions.
+const (
+	// AssignmentTransitionDemote is an incorrect review moving an assignment
+	// to a lower stage, or keeping it at the starting stage.
+	AssignmentTransitionDemote AssignmentTransition = "demote"
+
+	// AssignmentTransitionPromote is a correct review moving an assignment up
+	// one stage.
+	AssignmentTransitionPromote AssignmentTransition = "promote"
+
+	// AssignmentTransitionResurrect is a burned assignment being resurrected
+	// to the starting stage.
+	AssignmentTransitionResurrect AssignmentTransition = "resurrect"
+
+	// AssignmentTransitionReset is an assignment being returned to locked or
+	// to lessons by a reset.
+	AssignmentTransitionReset AssignmentTransition = "reset"
+
+	// AssignmentTransitionStart is a lesson being completed, moving an
+	// assignment to the starting stage.
+	AssignmentTransitionStart AssignmentTransition = "start"
+
+	// AssignmentTransitionUnlock is a locked assignment being unlocked and
+	// becoming available for lessons.
+	AssignmentTransitionUnlock AssignmentTransition = "unlock"
+)
+
+// AssignmentTransitionError is returned when a change between two assignment
+// states isn't possible in a spaced repetition system.
+type AssignmentTransitionError struct {
+	From *AssignmentState
+	To   *AssignmentState
+}
+
+// Error returns a description of the invalid transition.
+func (e *AssignmentTransitionError) Error() string {
+	return fmt.Sprintf("invalid assignment transition from %v (stage %v) to %v (stage %v)",
+		e.From, e.From.Stage, e.To, e.To.Stage)
+}
+
+// StageState returns the state of an unlocked assignment at the given SRS
+// stage position.
+//
+// Groups are derived from the system's stage positions rather than fixed
+// numbers. The unlocking stage is lessons, stages from the starting stage up
+// to the passing stage are apprentice, and the burning stage is burned.
+// Between passing and burning, the last stage is enlightened, the one before
+// it master, and the rest guru.
+//
+// Returns an error if the position isn't a stage of the system.
+func (d *SpacedRepetitionSystemData) StageState(position int) (*AssignmentState, error) {
+	if d.Stage(position) == nil {
+		return nil, fmt.Errorf("SRS stage %v isn't a stage of spaced repetition system %q", position, d.Name)
+	}
+
+	state := &AssignmentState{Stage: position}
+
+	switch {
+	case position == d.UnlockingStagePosition:
+		state.Group, state.GroupPosition, state.GroupSize = AssignmentStageGroupLesson, 1, 1
+
+	case position >= d.BurningStagePosition:
+		state.Group, state.GroupPosition, state.GroupSize = AssignmentStageGroupBurned, 1, 1
+
+	case position < d.PassingStagePosition:
+		state.Group = AssignmentStageGroupApprentice
+		state.GroupPosition = position - d.StartingStagePosition + 1
+		state.GroupSize = d.PassingStagePosition - d.StartingStagePosition
+
+	default:
+		numPassed := d.BurningStagePosition - d.PassingStagePosition
+		offset := position - d.PassingStagePosition
+
+		switch {
+		case offset > 0 && offset == numPassed-1:
+			state.Group, state.GroupPosition, state.GroupSize = AssignmentStageGroupEnlightened, 1, 1
+
+		case offset > 0 && offset == numPassed-2:
+			state.Group, state.GroupPosition, state.GroupSize = AssignmentStageGroupMaster, 1, 1
+
+		default:
+			numGuru := numPassed - 2
+			if numGuru < 1 {
+				numGuru = 1
+			}
+			state.Group = AssignmentStageGroupGuru
+			state.GroupPosition = offset + 1
+			state.GroupSize = numGuru
+		}
+	}
+
+	return state, nil
+}
+
+// Transition classifies a change between two assignment states, returning an
+// AssignmentTransitionError if it isn't possible in the system.
+//
+// Reviews move an assignment up one stage when correct, and down by at least
+// one stage (but not below the starting stage) when incorrect. Burned
+// assignments can only be resurrected, and any unlocked assignment can be
+// reset.
+func (d *SpacedRepetitionSystemData) Transition(from, to *AssignmentState) (AssignmentTransition, error) {
+	invalid := &AssignmentTransitionError{From: from, To: to}
+
+	switch {
+	case from.Group == AssignmentStageGroupLocked:
+		if to.Group == AssignmentStageGroupLesson {
+			return AssignmentTransitionUnlock, nil
+		}
+		return "", invalid
+
+	case to.Group == AssignmentStageGroupLocked || to.Group == AssignmentStageGroupLesson:
+		if from.Group == AssignmentStageGroupLesson && to.Group == AssignmentStageGroupLesson {
+			return "", invalid
+		}
+		return AssignmentTransitionReset, nil
+
+	case from.Group == AssignmentStageGroupLesson:
+		if to.Stage == d.StartingStagePosition {
+			return AssignmentTransitionStart, nil
+		}
+		return "", invalid
+
+	case from.Group == AssignmentStageGroupBurned:
+		if to.Stage == d.StartingStagePosition {
+			return AssignmentTransitionResurrect, nil
+		}
+		return "", invalid
+
+	case to.Stage == from.Stage+1:
+		return AssignmentTransitionPromote, nil
+
+	case to.Stage >= d.StartingStagePosition &&
+		(to.Stage < from.Stage || to.Stage == d.StartingStagePosition && from.Stage == d.StartingStagePosition):
+		return AssignmentTransitionDemote, nil
+	}
+
+	return "", invalid
+}
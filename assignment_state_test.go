@@ -0,0 +1,180 @@
+package wanikaniapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewAssignmentState(t *testing.T) {
+	srs := mustLoadSRS(t, "spaced_repetition_system.json")
+	now := time.Now()
+
+	t.Run("Locked", func(t *testing.T) {
+		state, err := wanikaniapi.NewAssignmentState(nil, srs)
+		assert.NoError(t, err)
+		assert.Equal(t, wanikaniapi.AssignmentStageGroupLocked, state.Group)
+		assert.Equal(t, "Locked", state.String())
+
+		state, err = wanikaniapi.NewAssignmentState(&wanikaniapi.Assignment{
+			Data: &wanikaniapi.AssignmentData{},
+		}, srs)
+		assert.NoError(t, err)
+		assert.Equal(t, wanikaniapi.AssignmentStageGroupLocked, state.Group)
+	})
+
+	t.Run("NoSRS", func(t *testing.T) {
+		_, err := wanikaniapi.NewAssignmentState(nil, nil)
+		assert.EqualError(t, err, "spaced repetition system with data must be given")
+
+		_, err = wanikaniapi.NewAssignmentState(&wanikaniapi.Assignment{
+			Data: &wanikaniapi.AssignmentData{UnlockedAt: &now},
+		}, &wanikaniapi.SpacedRepetitionSystem{})
+		assert.EqualError(t, err, "spaced repetition system with data must be given")
+	})
+
+	t.Run("Lesson", func(t *testing.T) {
+		state, err := wanikaniapi.NewAssignmentState(&wanikaniapi.Assignment{
+			Data: &wanikaniapi.AssignmentData{SRSStage: 0, UnlockedAt: &now},
+		}, srs)
+		assert.NoError(t, err)
+		assert.Equal(t, wanikaniapi.AssignmentStageGroupLesson, state.Group)
+		assert.Equal(t, "Lesson", state.String())
+	})
+
+	t.Run("Fixture", func(t *testing.T) {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "assignment.json"))
+		assert.NoError(t, err)
+
+		assignment := &wanikaniapi.Assignment{}
+		assert.NoError(t, json.Unmarshal(data, assignment))
+
+		state, err := wanikaniapi.NewAssignmentState(assignment, srs)
+		assert.NoError(t, err)
+		assert.Equal(t, "Enlightened", state.String())
+		assert.True(t, state.Group.Passed())
+	})
+
+	t.Run("UnknownStage", func(t *testing.T) {
+		_, err := wanikaniapi.NewAssignmentState(&wanikaniapi.Assignment{
+			Data: &wanikaniapi.AssignmentData{SRSStage: 10, UnlockedAt: &now},
+		}, srs)
+		assert.EqualError(t, err,
+			`SRS stage 10 isn't a stage of spaced repetition system "Default system for dictionary subjects"`)
+	})
+}
+
+func TestSpacedRepetitionSystemDataStageState(t *testing.T) {
+	// The default and accelerated systems have different intervals, but the
+	// same stage positions.
+	for _, srs := range []*wanikaniapi.SpacedRepetitionSystem{
+		mustLoadSRS(t, "spaced_repetition_system.json"),
+		mustLoadSRS(t, "spaced_repetition_system_accelerated.json"),
+	} {
+		t.Run(srs.Data.Name, func(t *testing.T) {
+			expected := []string{
+				"Lesson",
+				"Apprentice 1", "Apprentice 2", "Apprentice 3", "Apprentice 4",
+				"Guru 1", "Guru 2",
+				"Master",
+				"Enlightened",
+				"Burned",
+			}
+
+			for position, name := range expected {
+				state, err := srs.Data.StageState(position)
+				assert.NoError(t, err)
+				assert.Equal(t, name, state.String())
+				assert.Equal(t, position, state.Stage)
+				assert.Equal(t, position >= srs.Data.PassingStagePosition, state.Group.Passed())
+			}
+		})
+	}
+
+	t.Run("DerivedFromPositions", func(t *testing.T) {
+		// A hypothetical system with fewer stages gets groups derived from
+		// its positions.
+		data := &wanikaniapi.SpacedRepetitionSystemData{
+			UnlockingStagePosition: 0,
+			StartingStagePosition:  1,
+			PassingStagePosition:   3,
+			BurningStagePosition:   6,
+		}
+		for position := 0; position <= 6; position++ {
+			data.Stages = append(data.Stages, &wanikaniapi.SpacedRepetitionSystemStagedObject{Position: position})
+		}
+
+		var names []string
+		for position := 0; position <= 6; position++ {
+			state, err := data.StageState(position)
+			assert.NoError(t, err)
+			names = append(names, state.String())
+		}
+
+		assert.Equal(t, []string{
+			"Lesson", "Apprentice 1", "Apprentice 2", "Guru", "Master", "Enlightened", "Burned",
+		}, names)
+	})
+}
+
+func TestSpacedRepetitionSystemDataTransition(t *testing.T) {
+	srs := mustLoadSRS(t, "spaced_repetition_system.json")
+
+	locked, err := wanikaniapi.NewAssignmentState(nil, srs)
+	assert.NoError(t, err)
+
+	stage := func(position int) *wanikaniapi.AssignmentState {
+		state, err := srs.Data.StageState(position)
+		assert.NoError(t, err)
+		return state
+	}
+
+	testCases := []struct {
+		from, to *wanikaniapi.AssignmentState
+		expected wanikaniapi.AssignmentTransition
+	}{
+		{locked, stage(0), wanikaniapi.AssignmentTransitionUnlock},
+		{stage(0), stage(1), wanikaniapi.AssignmentTransitionStart},
+		{stage(1), stage(2), wanikaniapi.AssignmentTransitionPromote},
+		{stage(8), stage(9), wanikaniapi.AssignmentTransitionPromote},
+		{stage(1), stage(1), wanikaniapi.AssignmentTransitionDemote},
+		{stage(4), stage(3), wanikaniapi.AssignmentTransitionDemote},
+		{stage(8), stage(1), wanikaniapi.AssignmentTransitionDemote},
+		{stage(9), stage(1), wanikaniapi.AssignmentTransitionResurrect},
+		{stage(6), stage(0), wanikaniapi.AssignmentTransitionReset},
+		{stage(9), locked, wanikaniapi.AssignmentTransitionReset},
+
+		{locked, stage(1), ""},
+		{stage(0), stage(0), ""},
+		{stage(0), stage(2), ""},
+		{stage(1), stage(3), ""},
+		{stage(4), stage(4), ""},
+		{stage(9), stage(8), ""},
+		{stage(9), stage(5), ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.from.String()+"To"+tc.to.String(), func(t *testing.T) {
+			transition, err := srs.Data.Transition(tc.from, tc.to)
+
+			if tc.expected == "" {
+				var transitionErr *wanikaniapi.AssignmentTransitionError
+				assert.True(t, errors.As(err, &transitionErr))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, transition)
+		})
+	}
+
+	_, err = srs.Data.Transition(stage(1), stage(3))
+	assert.EqualError(t, err,
+		"invalid assignment transition from Apprentice 1 (stage 1) to Apprentice 3 (stage 3)")
+}
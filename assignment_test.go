@@ -27,6 +27,21 @@ func TestAssignmentList(t *testing.T) {
 	assert.Equal(t, "levels=1,2,3&started=true", wktesting.MustQueryUnescape(req.Query))
 }
 
+func TestAssignmentListSubjectTypes(t *testing.T) {
+	client := wktesting.LocalClient()
+
+	_, err := client.AssignmentList(&wanikaniapi.AssignmentListParams{
+		SubjectTypes: []wanikaniapi.WKObjectType{
+			wanikaniapi.ObjectTypeKanaVocabulary,
+			wanikaniapi.ObjectTypeVocabulary,
+		},
+	})
+	assert.NoError(t, err)
+
+	req := client.RecordedRequests[0]
+	assert.Equal(t, "subject_types=kana_vocabulary,vocabulary", wktesting.MustQueryUnescape(req.Query))
+}
+
 func TestAssignmentGet(t *testing.T) {
 	client := wktesting.LocalClient()
 
@@ -0,0 +1,202 @@
+package wanikaniapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewAudioDownloader returns a new downloader for pronounciation audio.
+func NewAudioDownloader(config *AudioDownloaderConfig) *AudioDownloader {
+	return &AudioDownloader{
+		Dir:        config.Dir,
+		HTTPClient: config.HTTPClient,
+		Logger:     config.Logger,
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// AudioDownloader downloads pronounciation audio and caches it on disk so
+// that each audio file is only downloaded once.
+//
+// Files are keyed by their URL, so a cached file is reused for as long as
+// WaniKani serves the audio from the same URL.
+type AudioDownloader struct {
+	// Dir is the directory in which audio files are cached. It's created if
+	// it doesn't exist.
+	Dir string
+
+	// HTTPClient is the HTTP client used to download audio files. Audio is
+	// served from WaniKani's CDN and doesn't need an API token. Defaults to a
+	// new http.Client if nil.
+	HTTPClient *http.Client
+
+	// Logger is the logger to send logging messages to. Defaults to a
+	// LeveledLogger that only shows errors if nil.
+	Logger LeveledLoggerInterface
+}
+
+// Download returns the path to a cached copy of the given audio on disk,
+// downloading it first if it's not already cached.
+func (d *AudioDownloader) Download(audio *SubjectVocabularyPronounciationAudio) (string, error) {
+	httpClient := d.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	logger := d.Logger
+	if logger == nil {
+		logger = &LeveledLogger{Level: LevelError}
+	}
+
+	path := d.Path(audio)
+
+	if _, err := os.Stat(path); err == nil {
+		logger.Debugf("Audio cached; url=%v, path=%v", audio.URL, path)
+		return path, nil
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("error checking for cached audio: %w", err)
+	}
+
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("error creating audio directory: %w", err)
+	}
+
+	logger.Debugf("Downloading audio; url=%v, path=%v", audio.URL, path)
+
+	req, err := http.NewRequest("GET", audio.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating audio request: %w", err)
+	}
+
+	_, err = downloadFile(httpClient, req, d.Dir, "audio", func(string) string { return path })
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Path returns the path at which the given audio is cached. The file may not
+// exist yet.
+func (d *AudioDownloader) Path(audio *SubjectVocabularyPronounciationAudio) string {
+	sum := sha256.Sum256([]byte(audio.URL))
+	return filepath.Join(d.Dir, hex.EncodeToString(sum[:])+contentTypeExtensions[audio.ContentType])
+}
+
+// AudioDownloaderConfig specifies configuration for an AudioDownloader.
+type AudioDownloaderConfig struct {
+	// Dir is the directory in which audio files are cached.
+	Dir string
+
+	// HTTPClient is an HTTP client to use for downloads. Defaults to a new
+	// http.Client.
+	HTTPClient *http.Client
+
+	// Logger is the logger to send logging messages to. Defaults to a
+	// LeveledLogger that only shows errors.
+	Logger LeveledLoggerInterface
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// fileDownload is the result of downloadFile.
+type fileDownload struct {
+	// header is the header of the response.
+	header http.Header
+
+	// notModified is true if the server responded that the file hasn't
+	// changed, in which case nothing was written.
+	notModified bool
+
+	// path is the path that the file was written to.
+	path string
+
+	// sha256 is the hex-encoded SHA-256 hash of the file's contents.
+	sha256 string
+
+	// size is the size of the file in bytes.
+	size int64
+}
+
+// downloadFile makes the given request and writes the response body to the
+// path returned by pathFor, which is passed the hex-encoded SHA-256 hash of
+// the body. The body is written to a temporary file in dir first and moved
+// into place once complete so that an interrupted download never leaves a
+// partial file behind. noun describes the file in error messages.
+//
+// A 304 response is returned with notModified set if the request was
+// conditional. Any other status besides 200 is an error.
+func downloadFile(httpClient *http.Client, req *http.Request, dir, noun string, pathFor func(sum string) string) (*fileDownload, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", noun, err)
+	}
+	defer resp.Body.Close()
+
+	conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+	if resp.StatusCode == http.StatusNotModified && conditional {
+		return &fileDownload{header: resp.Header, notModified: true}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading %s: unexpected status %v from %v",
+			noun, resp.StatusCode, req.URL)
+	}
+
+	tmpFile, err := ioutil.TempFile(dir, ".download-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary %s file: %w", noun, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmpFile, hash), resp.Body)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error writing %s: %w", noun, err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	path := pathFor(sum)
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return nil, fmt.Errorf("error moving %s into place: %w", noun, err)
+	}
+
+	return &fileDownload{header: resp.Header, path: path, sha256: sum, size: size}, nil
+}
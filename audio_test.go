@@ -0,0 +1,103 @@
+package wanikaniapi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestAudioDownloader(t *testing.T) {
+	var numRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+
+		if r.URL.Path == "/missing.mp3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte("audio at " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "wanikaniapi-audio")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	downloader := wanikaniapi.NewAudioDownloader(&wanikaniapi.AudioDownloaderConfig{
+		Dir: filepath.Join(dir, "audio"),
+	})
+
+	audio := &wanikaniapi.SubjectVocabularyPronounciationAudio{
+		ContentType: wanikaniapi.ContentTypeAudioMPEG,
+		URL:         server.URL + "/ichi.mp3",
+	}
+
+	t.Run("DownloadsOnce", func(t *testing.T) {
+		path, err := downloader.Download(audio)
+		assert.NoError(t, err)
+		assert.Equal(t, downloader.Path(audio), path)
+		assert.Equal(t, ".mp3", filepath.Ext(path))
+		assert.Equal(t, 1, numRequests)
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "audio at /ichi.mp3", string(data))
+
+		path, err = downloader.Download(audio)
+		assert.NoError(t, err)
+		assert.Equal(t, downloader.Path(audio), path)
+		assert.Equal(t, 1, numRequests)
+	})
+
+	t.Run("KeyedByURL", func(t *testing.T) {
+		other := &wanikaniapi.SubjectVocabularyPronounciationAudio{
+			ContentType: wanikaniapi.ContentTypeAudioOgg,
+			URL:         server.URL + "/ni.ogg",
+		}
+		assert.NotEqual(t, downloader.Path(audio), downloader.Path(other))
+
+		path, err := downloader.Download(other)
+		assert.NoError(t, err)
+		assert.Equal(t, ".ogg", filepath.Ext(path))
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "audio at /ni.ogg", string(data))
+	})
+
+	t.Run("HTTPError", func(t *testing.T) {
+		missing := &wanikaniapi.SubjectVocabularyPronounciationAudio{
+			ContentType: wanikaniapi.ContentTypeAudioMPEG,
+			URL:         server.URL + "/missing.mp3",
+		}
+
+		_, err := downloader.Download(missing)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status 404")
+
+		// Nothing is left behind in the cache.
+		files, err := ioutil.ReadDir(filepath.Join(dir, "audio"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(files))
+	})
+
+	t.Run("Literal", func(t *testing.T) {
+		// Not created with NewAudioDownloader, so HTTPClient and Logger are
+		// nil and fall back to defaults.
+		literal := &wanikaniapi.AudioDownloader{Dir: filepath.Join(dir, "literal")}
+
+		path, err := literal.Download(audio)
+		assert.NoError(t, err)
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "audio at /ichi.mp3", string(data))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,8 +13,11 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -73,6 +77,7 @@ func Time(t time.Time) *WKTime {
 const (
 	ObjectTypeAssignment             = WKObjectType("assignment")
 	ObjectTypeCollection             = WKObjectType("collection")
+	ObjectTypeKanaVocabulary         = WKObjectType("kana_vocabulary")
 	ObjectTypeKanji                  = WKObjectType("kanji")
 	ObjectTypeLevelProgression       = WKObjectType("level_progression")
 	ObjectTypeRadical                = WKObjectType("radical")
@@ -123,17 +128,34 @@ type Client struct {
 	// APIToken is the WaniKani API token to use for authentication.
 	APIToken string
 
+	// Clock is the source of the current time used by the client. Defaults to
+	// the system clock.
+	Clock Clock
+
 	// Logger is the logger to send logging messages to.
 	Logger LeveledLoggerInterface
 
+	// MaxResponseBytes is the maximum size of a response body that the client
+	// will read. Responses larger than this produce a ResponseTooLargeError.
+	// Zero means no limit.
+	MaxResponseBytes int64
+
 	// MaxRetries is the maximum number of retries for network errors and other
 	// types of error.
 	MaxRetries int
 
+	// OnDrift receives issues found in strict decoding mode. If nil, issues
+	// are logged as warnings to Logger instead.
+	OnDrift func(*DriftIssue)
+
 	// NoRetrySleep forces the client to not sleep on retries. This is for
-	// testing only. Don't use.
+	// testing only. Don't use. Prefer injecting a Sleeper instead.
 	NoRetrySleep bool
 
+	// Rand is the source of randomness used to jitter retry sleeps. Defaults
+	// to the global source in math/rand.
+	Rand Rand
+
 	// RecordMode stubs out any actual HTTP calls, and instead starts storing
 	// request data to RecordedRequests.
 	RecordMode bool
@@ -146,14 +168,36 @@ type Client struct {
 	// This is generally used only in tests.
 	RecordedResponses []*RecordedResponse
 
+	// RetainRaw causes the raw JSON of each decoded object to be kept in its
+	// Object.Raw for as long as the object is alive. See
+	// ClientConfig.RetainRaw.
+	RetainRaw bool
+
+	// Sleeper is used to sleep between retries. Defaults to time.Sleep.
+	Sleeper Sleeper
+
+	// StrictDecoding turns on strict decoding mode, in which responses are
+	// compared against the structs they're decoded into, and any fields that
+	// are unknown or missing are reported to OnDrift.
+	StrictDecoding bool
+
 	baseURL    string
 	httpClient *http.Client
 }
 
 // NewClient returns a new WaniKani API client.
 func NewClient(config *ClientConfig) *Client {
+	var clock Clock
 	var httpClient *http.Client
 	var logger LeveledLoggerInterface
+	var rnd Rand
+	var sleeper Sleeper
+
+	if config.Clock == nil {
+		clock = systemClock{}
+	} else {
+		clock = config.Clock
+	}
 
 	if config.HTTPClient == nil {
 		httpClient = &http.Client{}
@@ -167,9 +211,29 @@ func NewClient(config *ClientConfig) *Client {
 		logger = config.Logger
 	}
 
+	if config.Rand == nil {
+		rnd = globalRand{}
+	} else {
+		rnd = config.Rand
+	}
+
+	if config.Sleeper == nil {
+		sleeper = systemSleeper{}
+	} else {
+		sleeper = config.Sleeper
+	}
+
 	return &Client{
-		APIToken: config.APIToken,
-		Logger:   logger,
+		APIToken:         config.APIToken,
+		Clock:            clock,
+		Logger:           logger,
+		MaxResponseBytes: config.MaxResponseBytes,
+		MaxRetries:       config.MaxRetries,
+		OnDrift:          config.OnDrift,
+		Rand:             rnd,
+		RetainRaw:        config.RetainRaw,
+		Sleeper:          sleeper,
+		StrictDecoding:   config.StrictDecoding,
 
 		baseURL:    WaniKaniAPIURL,
 		httpClient: httpClient,
@@ -262,6 +326,14 @@ func (c *Client) request(method, path string, params ParamsInterface, reqData in
 			break
 		}
 
+		// Once a streamed page has passed items to its caller, a retry would
+		// pass them again, so give up instead.
+		if streamer, ok := respObj.(*pageStreamer); ok && streamer.numYielded > 0 {
+			c.Logger.Errorf("Error after streaming %v item(s); not retrying: %v",
+				streamer.numYielded, err)
+			break
+		}
+
 		numRetries++
 		c.Logger.Errorf("Retryable error (retry: %v) %v", numRetries, err)
 
@@ -269,17 +341,12 @@ func (c *Client) request(method, path string, params ParamsInterface, reqData in
 			break
 		}
 
-		baseSleepSeconds := int(math.Pow(2, float64(numRetries)))
-
-		// Nanoseconds
-		sleepDuration := time.Duration(baseSleepSeconds) * time.Second
-
-		// Apply jitter by randomizing in the range of 75 to 100%
-		jitter := rand.Int63n(int64(sleepDuration / 4))
-		sleepDuration -= time.Duration(jitter)
-
 		if !c.NoRetrySleep {
-			time.Sleep(sleepDuration)
+			sleeper := c.Sleeper
+			if sleeper == nil {
+				sleeper = systemSleeper{}
+			}
+			sleeper.Sleep(c.retrySleepDuration(numRetries))
 		}
 	}
 
@@ -325,7 +392,7 @@ func (c *Client) requestOne(method, path, query, url string, params *Params, req
 
 	obj := respObj.GetObject()
 
-	var respBytes []byte
+	var respBody io.Reader
 	var statusCode int
 	if c.RecordMode {
 		c.RecordedRequests = append(c.RecordedRequests, &RecordedRequest{
@@ -336,6 +403,7 @@ func (c *Client) requestOne(method, path, query, url string, params *Params, req
 			Query:  query,
 		})
 
+		var respBytes []byte
 		statusCode = http.StatusOK
 		if len(c.RecordedResponses) > 0 {
 			var resp *RecordedResponse
@@ -347,6 +415,7 @@ func (c *Client) requestOne(method, path, query, url string, params *Params, req
 		if respBytes == nil {
 			respBytes = []byte("{}")
 		}
+		respBody = bytes.NewReader(respBytes)
 	} else {
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
@@ -368,10 +437,11 @@ func (c *Client) requestOne(method, path, query, url string, params *Params, req
 		}
 
 		statusCode = resp.StatusCode
-		respBytes, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil
-		}
+		respBody = resp.Body
+	}
+
+	if c.MaxResponseBytes > 0 {
+		respBody = &maxBytesReader{maxBytes: c.MaxResponseBytes, r: respBody, remaining: c.MaxResponseBytes}
 	}
 
 	if statusCode == http.StatusNotModified {
@@ -380,8 +450,13 @@ func (c *Client) requestOne(method, path, query, url string, params *Params, req
 	}
 
 	if statusCode != http.StatusOK {
+		respBytes, err := ioutil.ReadAll(respBody)
+		if err != nil {
+			return fmt.Errorf("error reading error response: %w", err)
+		}
+
 		var apiErr APIError
-		err := json.Unmarshal(respBytes, &apiErr)
+		err = json.Unmarshal(respBytes, &apiErr)
 		if err != nil {
 			return fmt.Errorf("error unmarshaling error response: %w", err)
 		}
@@ -389,14 +464,86 @@ func (c *Client) requestOne(method, path, query, url string, params *Params, req
 		return &apiErr
 	}
 
+	var drift *driftChecker
+	if c.StrictDecoding {
+		drift = &driftChecker{onIssue: c.reportDrift, requestPath: path}
+	}
+
+	if streamer, ok := respObj.(*pageStreamer); ok {
+		if err := streamer.decode(respBody, drift, c.RetainRaw); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		return nil
+	}
+
+	respBytes, err := ioutil.ReadAll(respBody)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
 	err = json.Unmarshal(respBytes, respObj)
 	if err != nil {
 		return fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
+	if drift != nil {
+		drift.check("", reflect.TypeOf(respObj), respBytes)
+	}
+
+	if c.RetainRaw {
+		retainRaw(reflect.ValueOf(respObj), respBytes)
+	}
+
 	return nil
 }
 
+func (c *Client) reportDrift(issue *DriftIssue) {
+	if c.OnDrift != nil {
+		c.OnDrift(issue)
+		return
+	}
+
+	c.Logger.Warnf("API drift detected: %v", issue)
+}
+
+// now returns the current time from the client's Clock, falling back to the
+// system clock for a Client that wasn't created with NewClient.
+func (c *Client) now() time.Time {
+	if c.Clock == nil {
+		return systemClock{}.Now()
+	}
+	return c.Clock.Now()
+}
+
+// retrySleepDuration returns the amount of time to sleep before making the
+// given retry. Sleeps back off exponentially, starting at two seconds for the
+// first retry, and are jittered into the range of 75 to 100% of their base
+// value.
+func (c *Client) retrySleepDuration(numRetries int) time.Duration {
+	baseSleepSeconds := int(math.Pow(2, float64(numRetries)))
+
+	// Nanoseconds
+	sleepDuration := time.Duration(baseSleepSeconds) * time.Second
+
+	rnd := c.Rand
+	if rnd == nil {
+		rnd = globalRand{}
+	}
+
+	// Apply jitter by randomizing in the range of 75 to 100%
+	jitter := rnd.Int63n(int64(sleepDuration / 4))
+	sleepDuration -= time.Duration(jitter)
+
+	return sleepDuration
+}
+
+var (
+	pageObjectType = reflect.TypeOf(PageObject{})
+	subjectType    = reflect.TypeOf(Subject{})
+	timeType       = reflect.TypeOf(time.Time{})
+	wkTimeType     = reflect.TypeOf(WKTime{})
+)
+
 // Regular expressions used to match a few error types that we know we don't
 // want to retry. Unfortunately these errors aren't typed so we match on the
 // error's message.
@@ -406,6 +553,12 @@ var (
 )
 
 func (c *Client) retryableErr(err error) bool {
+	// Don't retry a response that's too big. It'll be too big next time too.
+	var tooLargeErr *ResponseTooLargeError
+	if errors.As(err, &tooLargeErr) {
+		return false
+	}
+
 	if apiErr, ok := err.(*APIError); ok {
 		switch apiErr.StatusCode {
 		case http.StatusTooManyRequests:
@@ -443,6 +596,9 @@ type ClientConfig struct {
 	// APIToken is the WaniKani API token to use for authentication.
 	APIToken string
 
+	// Clock is a source of the current time. Defaults to the system clock.
+	Clock Clock
+
 	// HTTPClient is your own HTTP client. The library will otherwise use a
 	// parameter-less `&http.Client{}`, resulting in default everything.
 	HTTPClient *http.Client
@@ -450,9 +606,58 @@ type ClientConfig struct {
 	// Logger is the logger to send logging messages to.
 	Logger LeveledLoggerInterface
 
+	// MaxResponseBytes is the maximum size of a response body that the client
+	// will read. Responses larger than this produce a ResponseTooLargeError
+	// and aren't retried. Defaults to zero, which means no limit.
+	MaxResponseBytes int64
+
 	// MaxRetries is the maximum number of retries for network errors and other
 	// types of error. Defaults to zero.
 	MaxRetries int
+
+	// OnDrift receives issues found in strict decoding mode (see
+	// StrictDecoding). If left nil, issues are logged as warnings to Logger
+	// instead.
+	OnDrift func(*DriftIssue)
+
+	// Rand is a source of randomness used to jitter the sleep between
+	// retries. Defaults to the global source in math/rand. A *rand.Rand
+	// satisfies this interface, so a seeded one can be used to make jitter
+	// deterministic.
+	Rand Rand
+
+	// RetainRaw causes the raw JSON of every decoded object to be kept in its
+	// Object.Raw, including the page itself and each item in a page for list
+	// endpoints. This makes it possible to access fields that the library
+	// doesn't model yet with Object.RawField, or to pass objects through to
+	// other systems byte-for-byte.
+	//
+	// Defaults to false because it isn't cheap: every object keeps its own
+	// copy of its bytes, including objects nested in other objects, and those
+	// bytes stay alive for as long as the decoded result does. Expect memory
+	// used by results to be at least double what it would be otherwise.
+	RetainRaw bool
+
+	// Sleeper is used to sleep between retries. Defaults to time.Sleep. Inject
+	// your own to observe the backoff schedule without waiting on it.
+	Sleeper Sleeper
+
+	// StrictDecoding turns on strict decoding mode. In this mode every
+	// response is compared against the structs it's decoded into, and fields
+	// in the response that the library doesn't know about, or fields that the
+	// library expects but which weren't in the response, are reported as a
+	// DriftIssue to OnDrift.
+	//
+	// This is useful for detecting changes in WaniKani's API, but it costs
+	// extra decoding work, so it's off by default.
+	StrictDecoding bool
+}
+
+// Clock provides the current time. It's injectable so that time can be
+// controlled in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
 }
 
 // ListParams contains the common parameters for every list endpoint in the
@@ -497,7 +702,12 @@ type Object struct {
 	NotModified bool `json:"-"`
 
 	ObjectType WKObjectType `json:"object"`
-	URL        string       `json:"url"`
+
+	// Raw is the raw JSON that the object was decoded from. It's only
+	// populated if the client was configured with RetainRaw.
+	Raw json.RawMessage `json:"-"`
+
+	URL string `json:"url"`
 }
 
 // GetObject returns the underlying Object object.
@@ -505,6 +715,43 @@ func (o *Object) GetObject() *Object {
 	return o
 }
 
+// RawField decodes a field from the object's raw JSON into v, and is useful
+// for accessing fields that the library doesn't model yet. The field is found
+// by following path through nested JSON objects, so a new field in a
+// subject's data would be accessed like:
+//
+//	var newField string
+//	ok, err := subject.RawField(&newField, "data", "new_field")
+//
+// Returns false if the field wasn't present. Returns an error if the field
+// couldn't be decoded into v, or if raw JSON wasn't retained (see
+// ClientConfig.RetainRaw).
+func (o *Object) RawField(v interface{}, path ...string) (bool, error) {
+	if o.Raw == nil {
+		return false, fmt.Errorf("no raw JSON retained; set ClientConfig.RetainRaw to retain it")
+	}
+
+	raw := o.Raw
+	for i, name := range path {
+		var objMap map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &objMap); err != nil {
+			return false, fmt.Errorf("error decoding %v: %w", rawFieldPath(path[:i]), err)
+		}
+
+		var ok bool
+		raw, ok = objMap[name]
+		if !ok {
+			return false, nil
+		}
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("error decoding %v: %w", rawFieldPath(path), err)
+	}
+
+	return true, nil
+}
+
 // ObjectInterface is a common interface implemented by response structures.
 type ObjectInterface interface {
 	GetObject() *Object
@@ -558,6 +805,12 @@ type ParamsInterface interface {
 	GetParams() *Params
 }
 
+// Rand is a source of randomness. It's implemented by *rand.Rand.
+type Rand interface {
+	// Int63n returns a non-negative pseudo-random number in [0,n).
+	Int63n(n int64) int64
+}
+
 // RecordedRequest is a request recorded when RecordMode is on.
 type RecordedRequest struct {
 	Body   []byte
@@ -573,6 +826,25 @@ type RecordedResponse struct {
 	StatusCode int
 }
 
+// ResponseTooLargeError is returned when a response body exceeds the
+// client's MaxResponseBytes.
+type ResponseTooLargeError struct {
+	// MaxBytes is the limit that was exceeded.
+	MaxBytes int64
+}
+
+// Error returns a description of the exceeded limit.
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeded maximum size of %v bytes", e.MaxBytes)
+}
+
+// Sleeper sleeps for a given duration. It's injectable so that sleeps can be
+// skipped or observed in tests.
+type Sleeper interface {
+	// Sleep pauses for at least the given duration.
+	Sleep(d time.Duration)
+}
+
 // WKID represents a WaniKani API identifier.
 type WKID int64
 
@@ -594,6 +866,27 @@ func (t WKTime) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + t.Encode() + `"`), nil
 }
 
+// UnmarshalJSON is the inverse of MarshalJSON, decoding a WKTime from an
+// RFC3339 string. A JSON null leaves the time unchanged.
+func (t *WKTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("error decoding time: %w", err)
+	}
+
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("error parsing time: %w", err)
+	}
+
+	*t = WKTime(parsed)
+	return nil
+}
+
 //////////////////////////////////////////////////////////////////////////////
 //
 //
@@ -604,6 +897,398 @@ func (t WKTime) MarshalJSON() ([]byte, error) {
 //
 //////////////////////////////////////////////////////////////////////////////
 
+// maxBytesReader reads from an underlying reader, but produces a
+// ResponseTooLargeError instead of reading beyond maxBytes.
+type maxBytesReader struct {
+	maxBytes  int64
+	r         io.Reader
+	remaining int64
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, &ResponseTooLargeError{MaxBytes: r.maxBytes}
+	}
+
+	// Read one byte past the limit so that a body of exactly maxBytes isn't
+	// mistaken for one that's too large.
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n - 1, &ResponseTooLargeError{MaxBytes: r.maxBytes}
+	}
+
+	return n, err
+}
+
+// pageStreamer decodes a list response one item at a time instead of into a
+// fully materialized slice. Fields other than `data` are decoded into the
+// embedded PageObject.
+type pageStreamer struct {
+	PageObject
+
+	// newItem allocates a value for a single item of `data` to be decoded
+	// into.
+	newItem func() interface{}
+
+	// numYielded is the number of items that have been passed to onItem.
+	numYielded int
+
+	// onItem receives each decoded item.
+	onItem func(item interface{}) error
+}
+
+// decode decodes a page from r. If drift is non-nil, each item and the page
+// itself are checked for drift. If retain is true, raw JSON is retained on
+// each item and the page.
+func (s *pageStreamer) decode(r io.Reader, drift *driftChecker, retain bool) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected object key, got %v", tok)
+		}
+
+		if key != "data" {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			fields[key] = raw
+			continue
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == nil {
+			continue
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("expected `data` to be an array, got %v", tok)
+		}
+
+		for i := 0; dec.More(); i++ {
+			item := s.newItem()
+
+			if drift == nil && !retain {
+				if err := dec.Decode(item); err != nil {
+					return err
+				}
+			} else {
+				var raw json.RawMessage
+				if err := dec.Decode(&raw); err != nil {
+					return err
+				}
+				if err := json.Unmarshal(raw, item); err != nil {
+					return err
+				}
+				if drift != nil {
+					drift.check(fmt.Sprintf("data[%v]", i), reflect.TypeOf(item), raw)
+				}
+				if retain {
+					retainRaw(reflect.ValueOf(item), raw)
+				}
+			}
+
+			s.numYielded++
+			if err := s.onItem(item); err != nil {
+				return err
+			}
+		}
+
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	pageBytes, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(pageBytes, &s.PageObject); err != nil {
+		return err
+	}
+
+	if drift != nil {
+		drift.check("", reflect.TypeOf(s.PageObject), pageBytes)
+	}
+
+	// The page's raw JSON doesn't include `data` since its items weren't
+	// kept in memory.
+	if retain {
+		s.Raw = pageBytes
+	}
+
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, expected json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %v, got %v", expected, tok)
+	}
+
+	return nil
+}
+
+// jsonField is a field of a struct as seen by encoding/json.
+type jsonField struct {
+	// index is the index sequence of the field for fieldByIndex.
+	index []int
+
+	// optional is true for fields tagged with `omitempty`.
+	optional bool
+
+	typ reflect.Type
+}
+
+// jsonFields returns the JSON fields of a struct type keyed by name,
+// flattening embedded structs in the same way that encoding/json does.
+func jsonFields(typ reflect.Type) map[string]*jsonField {
+	fields := make(map[string]*jsonField)
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if comma := strings.Index(tag, ","); comma != -1 {
+			name, opts = tag[:comma], tag[comma+1:]
+		}
+
+		if field.Anonymous && name == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				// encoding/json ignores embedded pointers to unexported types.
+				if field.PkgPath != "" {
+					continue
+				}
+				embeddedType = embeddedType.Elem()
+			}
+
+			if embeddedType.Kind() == reflect.Struct {
+				for embeddedName, embeddedField := range jsonFields(embeddedType) {
+					// Fields on the outer struct take precedence.
+					if _, ok := fields[embeddedName]; !ok {
+						embeddedField.index = append([]int{i}, embeddedField.index...)
+						fields[embeddedName] = embeddedField
+					}
+				}
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields[name] = &jsonField{
+			index:    []int{i},
+			optional: strings.Contains(","+opts+",", ",omitempty,"),
+			typ:      field.Type,
+		}
+	}
+
+	return fields
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but returns false instead
+// of panicking when it would step through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// idOrNil returns a pointer to id, or nil if it's zero, for marshaling objects
+// that WaniKani sends without an `id`.
+func idOrNil(id WKID) *WKID {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// rawFieldPath describes a path passed to Object.RawField in error messages.
+func rawFieldPath(path []string) string {
+	if len(path) == 0 {
+		return "root"
+	}
+	return strings.Join(path, ".")
+}
+
+// retainRaw walks a decoded value alongside the raw JSON it was decoded from,
+// setting Object.Raw on every object that it finds.
+func retainRaw(v reflect.Value, raw json.RawMessage) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType || v.Type() == wkTimeType {
+			return
+		}
+
+		if v.CanAddr() {
+			// GetObject may be promoted from a nil embedded *Object.
+			if obj, ok := v.Addr().Interface().(ObjectInterface); ok && obj.GetObject() != nil {
+				obj.GetObject().Raw = raw
+			}
+		}
+
+		var objMap map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &objMap); err != nil {
+			return
+		}
+
+		for name, field := range jsonFields(v.Type()) {
+			if !mayContainObject(field.typ) {
+				continue
+			}
+
+			fieldRaw, ok := objMap[name]
+			if !ok {
+				continue
+			}
+
+			if fieldValue, ok := fieldByIndex(v, field.index); ok {
+				retainRaw(fieldValue, fieldRaw)
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if !mayContainObject(v.Type().Elem()) {
+			return
+		}
+
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return
+		}
+
+		for i := 0; i < v.Len() && i < len(elems); i++ {
+			retainRaw(v.Index(i), elems[i])
+		}
+	}
+}
+
+var objectInterfaceType = reflect.TypeOf((*ObjectInterface)(nil)).Elem()
+
+// mayContainObjectCache caches the results of mayContainObject by type.
+var mayContainObjectCache sync.Map
+
+// mayContainObject returns true if a value of the given type is, or may
+// contain, a value that implements ObjectInterface.
+func mayContainObject(typ reflect.Type) bool {
+	if cached, ok := mayContainObjectCache.Load(typ); ok {
+		return cached.(bool)
+	}
+
+	result := mayContainObjectVisited(typ, make(map[reflect.Type]bool))
+	mayContainObjectCache.Store(typ, result)
+	return result
+}
+
+func mayContainObjectVisited(typ reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[typ] {
+		return false
+	}
+	visited[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		return true
+
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return mayContainObjectVisited(typ.Elem(), visited)
+
+	case reflect.Struct:
+		if reflect.PtrTo(typ).Implements(objectInterfaceType) {
+			return true
+		}
+
+		for _, field := range jsonFields(typ) {
+			if mayContainObjectVisited(field.typ, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// globalRand is a Rand that uses the global source in math/rand.
+type globalRand struct{}
+
+func (globalRand) Int63n(n int64) int64 { return rand.Int63n(n) }
+
+// shuffle shuffles n elements with a Fisher-Yates shuffle using rnd, calling
+// swap to swap the elements at two indexes like rand.Shuffle.
+func shuffle(rnd Rand, n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, int(rnd.Int63n(int64(i+1))))
+	}
+}
+
+// systemClock is a Clock that uses the system clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// systemSleeper is a Sleeper that uses time.Sleep.
+type systemSleeper struct{}
+
+func (systemSleeper) Sleep(d time.Duration) { time.Sleep(d) }
+
 func joinIDs(ids []WKID, separator string) string {
 	var s string
 
@@ -637,7 +1322,7 @@ func joinObjectTypes(types []WKObjectType, separator string) string {
 
 	for i, typ := range types {
 		if i != 0 {
-			typ += ","
+			s += ","
 		}
 
 		s += string(typ)
@@ -651,7 +1336,7 @@ func joinStrings(strs []string, separator string) string {
 
 	for i, str := range strs {
 		if i != 0 {
-			str += ","
+			s += ","
 		}
 
 		s += str
@@ -2,7 +2,10 @@ package wanikaniapi_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"testing"
 	"time"
@@ -80,6 +83,124 @@ func TestClientIfNoneMatch(t *testing.T) {
 	assert.True(t, obj.NotModified)
 }
 
+func TestClientRetainRaw(t *testing.T) {
+	item1 := `{"id": 123, "object": "kanji", "data": {"characters": "一", "new_field": {"nested": 7}}}`
+	item2 := `{"id":124,"object":"kanji","data":{"characters":"二"}}`
+	body := `{
+		"object": "collection",
+		"pages": {"per_page": 1000, "next_url": null, "previous_url": null},
+		"data": [` + item1 + `, ` + item2 + `]
+	}`
+
+	t.Run("Page", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.RetainRaw = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: []byte(body)},
+		}
+
+		page, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, body, string(page.Raw))
+		assert.Equal(t, item1, string(page.Data[0].Raw))
+		assert.Equal(t, item2, string(page.Data[1].Raw))
+
+		var nested int
+		ok, err := page.Data[0].RawField(&nested, "data", "new_field", "nested")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 7, nested)
+
+		var missing string
+		ok, err = page.Data[1].RawField(&missing, "data", "new_field")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		var wrongType int
+		_, err = page.Data[0].RawField(&wrongType, "data", "characters")
+		assert.EqualError(t, err, "error decoding data.characters: json: cannot unmarshal string into Go value of type int")
+
+		_, err = page.Data[0].RawField(&wrongType, "data", "characters", "nested")
+		assert.Contains(t, err.Error(), "error decoding data.characters: ")
+
+		notObject := &wanikaniapi.Object{Raw: json.RawMessage(`[1]`)}
+		_, err = notObject.RawField(&wrongType, "data")
+		assert.Contains(t, err.Error(), "error decoding root: ")
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.RetainRaw = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: []byte(body)},
+		}
+
+		var raws []string
+		page, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{}, func(subject *wanikaniapi.Subject) error {
+			raws = append(raws, string(subject.Raw))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{item1, item2}, raws)
+
+		var perPage int
+		ok, err := page.RawField(&perPage, "pages", "per_page")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 1000, perPage)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		assignment := `{"id": 1422, "object": "assignment", "data": {"srs_stage": 1}}`
+		client := wktesting.LocalClient()
+		client.RetainRaw = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: []byte(`{"id": 72, "object": "review", "resources_updated": {"assignment": ` + assignment + `}}`)},
+		}
+
+		review, err := client.ReviewCreate(&wanikaniapi.ReviewCreateParams{SubjectID: wanikaniapi.ID(997)})
+		assert.NoError(t, err)
+		assert.Equal(t, assignment, string(review.ResourcesUpdated.Assignment.Raw))
+	})
+
+	t.Run("Off", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: []byte(body)},
+		}
+
+		page, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+		assert.NoError(t, err)
+		assert.Nil(t, page.Raw)
+		assert.Nil(t, page.Data[0].Raw)
+
+		var characters string
+		_, err = page.Data[0].RawField(&characters, "data", "characters")
+		assert.Error(t, err)
+	})
+}
+
+func TestRetainRawPointerEmbedded(t *testing.T) {
+	raw := []byte(`{"id": 1, "object": "report", "assignment": {"id": 2, "object": "assignment"}}`)
+
+	t.Run("Nil", func(t *testing.T) {
+		resource := &pointerEmbeddedResource{}
+		wanikaniapi.RetainRaw(resource, raw)
+		assert.Nil(t, resource.Object)
+		assert.Nil(t, resource.PointerEmbeddedAssignment)
+	})
+
+	t.Run("Decoded", func(t *testing.T) {
+		resource := &pointerEmbeddedResource{}
+		assert.NoError(t, json.Unmarshal(raw, resource))
+
+		wanikaniapi.RetainRaw(resource, raw)
+		assert.Equal(t, string(raw), string(resource.Raw))
+		assert.JSONEq(t, `{"id": 2, "object": "assignment"}`, string(resource.Assignment.Raw))
+	})
+}
+
 func TestClientRetry(t *testing.T) {
 	client := wktesting.LocalClient()
 	client.MaxRetries = 2
@@ -103,6 +224,189 @@ func TestClientRetry(t *testing.T) {
 	assert.Equal(t, 0, len(subjects.Data))
 }
 
+func TestClientRetrySchedule(t *testing.T) {
+	rateLimited := &wanikaniapi.RecordedResponse{StatusCode: http.StatusTooManyRequests, Body: []byte(`{
+		"code": 429,
+		"error": "You are rate limited"
+	}`)}
+
+	t.Run("MaxJitter", func(t *testing.T) {
+		sleeper := &recordingSleeper{}
+		client := wanikaniapi.NewClient(&wanikaniapi.ClientConfig{
+			MaxRetries: 3,
+			Rand:       maxRand{},
+			Sleeper:    sleeper,
+		})
+		client.RecordMode = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			rateLimited, rateLimited, rateLimited,
+			{StatusCode: http.StatusOK, Body: []byte(`{}`)},
+		}
+
+		_, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+		assert.NoError(t, err)
+
+		// 75% of 2s, 4s, and 8s plus the one nanosecond Int63n excludes.
+		assert.Equal(t, []time.Duration{
+			1500*time.Millisecond + 1,
+			3*time.Second + 1,
+			6*time.Second + 1,
+		}, sleeper.durations)
+	})
+
+	t.Run("NoJitter", func(t *testing.T) {
+		sleeper := &recordingSleeper{}
+		client := wanikaniapi.NewClient(&wanikaniapi.ClientConfig{
+			MaxRetries: 2,
+			Rand:       zeroRand{},
+			Sleeper:    sleeper,
+		})
+		client.RecordMode = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			rateLimited, rateLimited, rateLimited,
+		}
+
+		_, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+		assert.Equal(t, &wanikaniapi.APIError{
+			StatusCode: http.StatusTooManyRequests,
+			Message:    "You are rate limited",
+		}, err)
+
+		// No sleep after the final failed attempt.
+		assert.Equal(t, []time.Duration{2 * time.Second, 4 * time.Second}, sleeper.durations)
+	})
+
+	t.Run("SeededRand", func(t *testing.T) {
+		sleeper := &recordingSleeper{}
+		client := wanikaniapi.NewClient(&wanikaniapi.ClientConfig{
+			MaxRetries: 5,
+			Rand:       rand.New(rand.NewSource(1)),
+			Sleeper:    sleeper,
+		})
+		client.RecordMode = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			rateLimited, rateLimited, rateLimited, rateLimited, rateLimited,
+			{StatusCode: http.StatusOK, Body: []byte(`{}`)},
+		}
+
+		_, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 5, len(sleeper.durations))
+		for i, d := range sleeper.durations {
+			base := time.Duration(2<<i) * time.Second
+			assert.True(t, d > base*3/4, "sleep %v too short: %v", i, d)
+			assert.True(t, d <= base, "sleep %v too long: %v", i, d)
+		}
+	})
+}
+
+func TestClientMaxResponseBytes(t *testing.T) {
+	body := []byte(`{"data": [{"id": 123, "object": "kanji"}]}`)
+
+	t.Run("UnderLimit", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.MaxResponseBytes = int64(len(body))
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: body},
+		}
+
+		subjects, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(subjects.Data))
+	})
+
+	t.Run("OverLimit", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.MaxResponseBytes = int64(len(body)) - 1
+		client.MaxRetries = 2
+		client.NoRetrySleep = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: body},
+			{StatusCode: http.StatusOK, Body: body},
+		}
+
+		_, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+		var tooLargeErr *wanikaniapi.ResponseTooLargeError
+		assert.True(t, errors.As(err, &tooLargeErr))
+		assert.Equal(t, int64(len(body))-1, tooLargeErr.MaxBytes)
+
+		// Not retried.
+		assert.Equal(t, 1, len(client.RecordedRequests))
+	})
+
+	t.Run("OverLimitStream", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.MaxResponseBytes = 10
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: body},
+		}
+
+		_, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{},
+			func(*wanikaniapi.Subject) error { return nil })
+		var tooLargeErr *wanikaniapi.ResponseTooLargeError
+		assert.True(t, errors.As(err, &tooLargeErr))
+	})
+}
+
+func TestClientStreamRetry(t *testing.T) {
+	rateLimited := &wanikaniapi.RecordedResponse{StatusCode: http.StatusTooManyRequests, Body: []byte(`{
+		"code": 429,
+		"error": "You are rate limited"
+	}`)}
+
+	t.Run("RetriedBeforeYield", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.MaxRetries = 2
+		client.NoRetrySleep = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			rateLimited,
+			{StatusCode: http.StatusOK, Body: []byte(`{"data": [{"id": 123, "object": "kanji"}]}`)},
+		}
+
+		var numSubjects int
+		_, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{},
+			func(*wanikaniapi.Subject) error { numSubjects++; return nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 1, numSubjects)
+		assert.Equal(t, 2, len(client.RecordedRequests))
+	})
+
+	t.Run("NotRetriedAfterYield", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.MaxRetries = 2
+		client.NoRetrySleep = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: []byte(`{"data": [{"id": 123, "object": "kanji"}, {"id": `)},
+			{StatusCode: http.StatusOK, Body: []byte(`{"data": [{"id": 123, "object": "kanji"}]}`)},
+		}
+
+		var numSubjects int
+		_, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{},
+			func(*wanikaniapi.Subject) error { numSubjects++; return nil })
+		assert.Error(t, err)
+		assert.Equal(t, 1, numSubjects)
+		assert.Equal(t, 1, len(client.RecordedRequests))
+	})
+
+	t.Run("CallbackError", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.MaxRetries = 2
+		client.NoRetrySleep = true
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: []byte(`{"data": [{"id": 123, "object": "kanji"}, {"id": 124, "object": "kanji"}]}`)},
+		}
+
+		callbackErr := errors.New("stop")
+		var numSubjects int
+		_, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{},
+			func(*wanikaniapi.Subject) error { numSubjects++; return callbackErr })
+		assert.True(t, errors.Is(err, callbackErr))
+		assert.Equal(t, 1, numSubjects)
+		assert.Equal(t, 1, len(client.RecordedRequests))
+	})
+}
+
 func TestClientNoRetry(t *testing.T) {
 	client := wktesting.LocalClient()
 
@@ -233,6 +537,42 @@ func TestPageFullyLive(t *testing.T) {
 	t.Logf("num subjects paged before quitting: %v", len(subjects))
 }
 
+func TestNewClientDefaults(t *testing.T) {
+	client := wanikaniapi.NewClient(&wanikaniapi.ClientConfig{})
+	assert.NotNil(t, client.Clock)
+	assert.NotNil(t, client.Rand)
+	assert.NotNil(t, client.Sleeper)
+	assert.WithinDuration(t, time.Now(), client.Clock.Now(), time.Minute)
+
+	clock := fixedClock{time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+	client = wanikaniapi.NewClient(&wanikaniapi.ClientConfig{Clock: clock})
+	assert.Equal(t, clock.t, client.Clock.Now())
+}
+
+func TestClientLiteralDefaults(t *testing.T) {
+	sleeper := &recordingSleeper{}
+	client := &wanikaniapi.Client{
+		Logger:     &wanikaniapi.LeveledLogger{Level: wanikaniapi.LevelDebug},
+		MaxRetries: 1,
+		RecordMode: true,
+		RecordedResponses: []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusTooManyRequests, Body: []byte(`{
+				"code": 429,
+				"error": "You are rate limited"
+			}`)},
+			{StatusCode: http.StatusOK, Body: []byte(`{}`)},
+		},
+		Sleeper: sleeper,
+	}
+
+	// Nil Rand falls back to the global source.
+	_, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(sleeper.durations))
+	assert.True(t, sleeper.durations[0] > 1500*time.Millisecond)
+	assert.True(t, sleeper.durations[0] <= 2*time.Second)
+}
+
 func TestWKTimeMarshalJSON(t *testing.T) {
 	goT := time.Now()
 	wkT := wanikaniapi.WKTime(goT)
@@ -240,3 +580,54 @@ func TestWKTimeMarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, fmt.Sprintf(`"%s"`, goT.Format(time.RFC3339)), string(marshaled))
 }
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// PointerEmbeddedAssignment is embedded by pointer in
+// pointerEmbeddedResource. It's exported because encoding/json ignores
+// embedded pointers to unexported types.
+type PointerEmbeddedAssignment struct {
+	Assignment *wanikaniapi.Assignment `json:"assignment"`
+}
+
+// pointerEmbeddedResource embeds structs by pointer, one of which contains an
+// object.
+type pointerEmbeddedResource struct {
+	*wanikaniapi.Object
+	*PointerEmbeddedAssignment
+}
+
+// fixedClock is a Clock that always returns the same time.
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+// maxRand is a Rand that always returns the largest possible value.
+type maxRand struct{}
+
+func (maxRand) Int63n(n int64) int64 { return n - 1 }
+
+// recordingSleeper is a Sleeper that records sleeps instead of performing
+// them.
+type recordingSleeper struct {
+	durations []time.Duration
+}
+
+func (s *recordingSleeper) Sleep(d time.Duration) {
+	s.durations = append(s.durations, d)
+}
+
+// zeroRand is a Rand that always returns zero.
+type zeroRand struct{}
+
+func (zeroRand) Int63n(n int64) int64 { return 0 }
@@ -0,0 +1,228 @@
+package wanikaniapi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandur/wanikaniapi"
+	"github.com/brandur/wanikaniapi/wktesting"
+	assert "github.com/stretchr/testify/require"
+)
+
+//
+// Conformance tests decode fixtures in `testdata/` that mirror responses from
+// WaniKani's API in strict decoding mode, and check that every field in each
+// fixture is modeled by the library and that no expected field is missing.
+//
+
+func TestConformanceAssignment(t *testing.T) {
+	client := conformanceClient(t, "assignment.json")
+
+	assignment, err := client.AssignmentGet(&wanikaniapi.AssignmentGetParams{ID: wanikaniapi.ID(80463006)})
+	assert.NoError(t, err)
+	assert.Equal(t, wanikaniapi.WKID(80463006), assignment.ID)
+	assert.Equal(t, 8, assignment.Data.SRSStage)
+	assert.Equal(t, wanikaniapi.ObjectTypeRadical, assignment.Data.SubjectType)
+	assert.NotNil(t, assignment.Data.PassedAt)
+	assert.Nil(t, assignment.Data.BurnedAt)
+}
+
+func TestConformanceLevelProgression(t *testing.T) {
+	client := conformanceClient(t, "level_progression.json")
+
+	levelProgression, err := client.LevelProgressionGet(&wanikaniapi.LevelProgressionGetParams{ID: wanikaniapi.ID(49392)})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, levelProgression.Data.Level)
+	assert.NotNil(t, levelProgression.Data.StartedAt)
+}
+
+func TestConformanceReset(t *testing.T) {
+	client := conformanceClient(t, "reset.json")
+
+	reset, err := client.ResetGet(&wanikaniapi.ResetGetParams{ID: wanikaniapi.ID(234)})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, reset.Data.OriginalLevel)
+	assert.Equal(t, 8, reset.Data.TargetLevel)
+}
+
+func TestConformanceReview(t *testing.T) {
+	client := conformanceClient(t, "review.json")
+
+	review, err := client.ReviewGet(&wanikaniapi.ReviewGetParams{ID: wanikaniapi.ID(534342)})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, review.Data.StartingSRSStage)
+	assert.Equal(t, 2, review.Data.EndingSRSStage)
+	assert.Nil(t, review.ResourcesUpdated)
+}
+
+func TestConformanceReviewCreate(t *testing.T) {
+	client := conformanceClient(t, "review_create.json")
+
+	review, err := client.ReviewCreate(&wanikaniapi.ReviewCreateParams{SubjectID: wanikaniapi.ID(997)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, review.Data.IncorrectReadingAnswers)
+	assert.Equal(t, wanikaniapi.WKID(1422), review.ResourcesUpdated.Assignment.ID)
+	assert.Equal(t, 67, review.ResourcesUpdated.ReviewStatistic.Data.PercentageCorrect)
+}
+
+func TestConformanceReviewStatistic(t *testing.T) {
+	client := conformanceClient(t, "review_statistic.json")
+
+	reviewStatistic, err := client.ReviewStatisticGet(&wanikaniapi.ReviewStatisticGetParams{ID: wanikaniapi.ID(80461982)})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, reviewStatistic.Data.MeaningCurrentStreak)
+	assert.Equal(t, 100, reviewStatistic.Data.PercentageCorrect)
+}
+
+func TestConformanceSpacedRepetitionSystem(t *testing.T) {
+	client := conformanceClient(t, "spaced_repetition_system.json")
+
+	srs, err := client.SpacedRepetitionSystemGet(&wanikaniapi.SpacedRepetitionSystemGetParams{ID: wanikaniapi.ID(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, srs.Data.PassingStagePosition)
+	assert.Equal(t, 9, srs.Data.BurningStagePosition)
+	assert.Equal(t, 10, len(srs.Data.Stages))
+	assert.Nil(t, srs.Data.Stages[0].Interval)
+	assert.Equal(t, 14400, *srs.Data.Stages[1].Interval)
+	assert.Equal(t, "seconds", *srs.Data.Stages[1].IntervalUnit)
+}
+
+func TestConformanceStudyMaterial(t *testing.T) {
+	client := conformanceClient(t, "study_material.json")
+
+	studyMaterial, err := client.StudyMaterialGet(&wanikaniapi.StudyMaterialGetParams{ID: wanikaniapi.ID(65231)})
+	assert.NoError(t, err)
+	assert.Equal(t, "I like turtles", *studyMaterial.Data.MeaningNote)
+	assert.Equal(t, []string{"burn", "sizzle"}, studyMaterial.Data.MeaningSynonyms)
+}
+
+func TestConformanceSubjectCollection(t *testing.T) {
+	client := conformanceClient(t, "subject_collection.json")
+
+	page, err := client.SubjectList(&wanikaniapi.SubjectListParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2027), page.TotalCount)
+	assert.Equal(t, 1, len(page.Data))
+	assert.Equal(t, "一", page.Data[0].KanjiData.Characters)
+}
+
+func TestConformanceSubjectCollectionStream(t *testing.T) {
+	client := conformanceClient(t, "subject_collection.json")
+
+	var subjects []*wanikaniapi.Subject
+	page, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{}, func(subject *wanikaniapi.Subject) error {
+		subjects = append(subjects, subject)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2027), page.TotalCount)
+	assert.Equal(t, 1, len(subjects))
+}
+
+func TestConformanceSubjectKanaVocabulary(t *testing.T) {
+	client := conformanceClient(t, "subject_kana_vocabulary.json")
+
+	subject, err := client.SubjectGet(&wanikaniapi.SubjectGetParams{ID: wanikaniapi.ID(9210)})
+	assert.NoError(t, err)
+	assert.Equal(t, wanikaniapi.ObjectTypeKanaVocabulary, subject.ObjectType)
+	assert.NotNil(t, subject.KanaVocabularyData)
+	assert.Equal(t, "おやつ", subject.KanaVocabularyData.Characters)
+	assert.Equal(t, 8, subject.KanaVocabularyData.Level)
+	assert.Equal(t, 1, len(subject.KanaVocabularyData.PronounciationAudios))
+}
+
+func TestConformanceSubjectKanji(t *testing.T) {
+	client := conformanceClient(t, "subject_kanji.json")
+
+	subject, err := client.SubjectGet(&wanikaniapi.SubjectGetParams{ID: wanikaniapi.ID(440)})
+	assert.NoError(t, err)
+	assert.NotNil(t, subject.KanjiData)
+	assert.Equal(t, "一", subject.KanjiData.Characters)
+	assert.Contains(t, subject.KanjiData.MeaningMnemonic, "<kanji>One</kanji>")
+	assert.Contains(t, subject.KanjiData.ReadingMnemonic, "weird sensation")
+	assert.Equal(t, 3, len(subject.KanjiData.Readings))
+	assert.Equal(t, wanikaniapi.SubjectKanjiReadingTypeOnyomi, subject.KanjiData.Readings[0].Type)
+}
+
+func TestConformanceSubjectRadical(t *testing.T) {
+	client := conformanceClient(t, "subject_radical.json")
+
+	subject, err := client.SubjectGet(&wanikaniapi.SubjectGetParams{ID: wanikaniapi.ID(1)})
+	assert.NoError(t, err)
+	assert.NotNil(t, subject.RadicalData)
+	assert.Equal(t, "一", *subject.RadicalData.Characters)
+	assert.Equal(t, 2, len(subject.RadicalData.CharacterImages))
+	assert.Contains(t, subject.RadicalData.MeaningMnemonic, "<radical>ground</radical>")
+}
+
+func TestConformanceSubjectVocabulary(t *testing.T) {
+	client := conformanceClient(t, "subject_vocabulary.json")
+
+	subject, err := client.SubjectGet(&wanikaniapi.SubjectGetParams{ID: wanikaniapi.ID(2467)})
+	assert.NoError(t, err)
+	assert.NotNil(t, subject.VocabularyData)
+	assert.Contains(t, subject.VocabularyData.MeaningMnemonic, "<vocabulary>one</vocabulary>")
+	assert.Contains(t, subject.VocabularyData.ReadingMnemonic, "kun'yomi")
+	assert.Equal(t, 1, len(subject.VocabularyData.Readings))
+	assert.Equal(t, "いち", subject.VocabularyData.Readings[0].Reading)
+	assert.Equal(t, 2, len(subject.VocabularyData.PronounciationAudios))
+}
+
+func TestConformanceSummary(t *testing.T) {
+	client := conformanceClient(t, "summary.json")
+
+	summary, err := client.SummaryGet(&wanikaniapi.SummaryGetParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, wanikaniapi.ObjectTypeReport, summary.ObjectType)
+	assert.Equal(t, []wanikaniapi.WKID{25, 26}, summary.Data.Lessons[0].SubjectIDs)
+	assert.Equal(t, 2, len(summary.Data.Reviews))
+}
+
+func TestConformanceUser(t *testing.T) {
+	client := conformanceClient(t, "user.json")
+
+	user, err := client.UserGet(&wanikaniapi.UserGetParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, "example_user", user.Data.Username)
+	assert.Equal(t, 10, user.Data.Preferences.LessonsBatchSize)
+	assert.Equal(t, "shuffled", user.Data.Preferences.ReviewsPresentationOrder)
+	assert.Equal(t, 60, user.Data.Subscription.MaxLevelGranted)
+}
+
+func TestConformanceVoiceActor(t *testing.T) {
+	client := conformanceClient(t, "voice_actor.json")
+
+	voiceActor, err := client.VoiceActorGet(&wanikaniapi.VoiceActorGetParams{ID: wanikaniapi.ID(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, "Kenichi", voiceActor.Data.Name)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// conformanceClient returns a local client in strict decoding mode that will
+// respond with the given fixture and fail the test on any drift.
+func conformanceClient(t *testing.T, fixture string) *wanikaniapi.Client {
+	body, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+
+	client := wktesting.LocalClient()
+	client.StrictDecoding = true
+	client.OnDrift = func(issue *wanikaniapi.DriftIssue) {
+		t.Errorf("drift in %s: %v", fixture, issue)
+	}
+	client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+		{StatusCode: http.StatusOK, Body: body},
+	}
+
+	return client
+}
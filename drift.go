@@ -0,0 +1,205 @@
+package wanikaniapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// DriftIssue describes a difference between a response from WaniKani's API
+// and the structs the library decodes it into. Issues are only detected when
+// the client is in strict decoding mode (see ClientConfig.StrictDecoding).
+//
+// Drift usually means that WaniKani has added a field that the library doesn't
+// model yet, or that a struct tag doesn't match the field's name in the API,
+// in which case the field silently decodes to its zero value.
+type DriftIssue struct {
+	// Kind is the kind of difference that was found.
+	Kind DriftKind
+
+	// Path is the location of the field in the response like
+	// `data[3].data.meaning_mnemonic`.
+	Path string
+
+	// RequestPath is the path of the API request that produced the response
+	// like `/v2/subjects`.
+	RequestPath string
+}
+
+// String returns a human-readable description of the issue.
+func (i *DriftIssue) String() string {
+	return fmt.Sprintf("%s: %s (request: %s)", i.Kind, i.Path, i.RequestPath)
+}
+
+// DriftKind is a kind of DriftIssue.
+type DriftKind string
+
+// All possible kinds of drift issues.
+const (
+	// DriftKindMissingField indicates a field that the library expects that
+	// wasn't present in a response.
+	DriftKindMissingField DriftKind = "missing_field"
+
+	// DriftKindUnknownField indicates a field that was present in a response,
+	// but which the library doesn't model.
+	DriftKindUnknownField DriftKind = "unknown_field"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// driftChecker compares raw response JSON against the struct type it was
+// decoded into, reporting fields that appear on one side but not the other.
+type driftChecker struct {
+	onIssue     func(*DriftIssue)
+	requestPath string
+}
+
+// subjectDataTypes maps subject object types to the type that their `data`
+// field is decoded into.
+var subjectDataTypes = map[WKObjectType]reflect.Type{
+	ObjectTypeKanaVocabulary: reflect.TypeOf(SubjectKanaVocabularyData{}),
+	ObjectTypeKanji:          reflect.TypeOf(SubjectKanjiData{}),
+	ObjectTypeRadical:        reflect.TypeOf(SubjectRadicalData{}),
+	ObjectTypeVocabulary:     reflect.TypeOf(SubjectVocabularyData{}),
+}
+
+func (d *driftChecker) check(path string, typ reflect.Type, raw json.RawMessage) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return
+	}
+
+	switch {
+	case typ == timeType || typ == wkTimeType:
+		return
+
+	case typ == subjectType:
+		d.checkSubject(path, raw)
+
+	case typ.Kind() == reflect.Struct:
+		fields := jsonFields(typ)
+		if field, ok := fields["id"]; ok && isIDlessType(typ) {
+			field.optional = true
+		}
+		d.checkFields(path, fields, raw)
+
+	case typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return
+		}
+
+		for i, elem := range elems {
+			d.check(fmt.Sprintf("%s[%v]", path, i), typ.Elem(), elem)
+		}
+	}
+}
+
+// checkFields checks a JSON object against a set of expected fields.
+func (d *driftChecker) checkFields(path string, fields map[string]*jsonField, raw json.RawMessage) {
+	var objMap map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &objMap); err != nil {
+		return
+	}
+
+	for _, name := range sortedKeys(objMap) {
+		field, ok := fields[name]
+		if !ok {
+			d.report(DriftKindUnknownField, joinDriftPath(path, name))
+			continue
+		}
+
+		if field.typ != nil {
+			d.check(joinDriftPath(path, name), field.typ, objMap[name])
+		}
+	}
+
+	for _, name := range sortedFieldNames(fields) {
+		if _, ok := objMap[name]; !ok && !fields[name].optional {
+			d.report(DriftKindMissingField, joinDriftPath(path, name))
+		}
+	}
+}
+
+// checkSubject checks a subject, whose `data` field is decoded into a
+// different type depending on the subject's object type.
+func (d *driftChecker) checkSubject(path string, raw json.RawMessage) {
+	var obj Object
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return
+	}
+
+	fields := jsonFields(reflect.TypeOf(Object{}))
+	fields["data"] = &jsonField{typ: subjectDataTypes[obj.ObjectType]}
+
+	d.checkFields(path, fields, raw)
+}
+
+func (d *driftChecker) report(kind DriftKind, path string) {
+	d.onIssue(&DriftIssue{Kind: kind, Path: path, RequestPath: d.requestPath})
+}
+
+// idlessTypes are the types of objects that WaniKani sends without an `id`.
+// Collections are handled separately in isIDlessType.
+var idlessTypes = map[reflect.Type]bool{
+	reflect.TypeOf(Summary{}): true,
+	reflect.TypeOf(User{}):    true,
+}
+
+// isIDlessType returns true if objects of the given type are sent without an
+// `id`, which is the case for the user, the summary, and collections.
+func isIDlessType(typ reflect.Type) bool {
+	if idlessTypes[typ] || typ == pageObjectType {
+		return true
+	}
+
+	field, ok := typ.FieldByName("PageObject")
+	return ok && field.Anonymous
+}
+
+func joinDriftPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedFieldNames(m map[string]*jsonField) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
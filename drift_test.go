@@ -0,0 +1,198 @@
+package wanikaniapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/brandur/wanikaniapi"
+	"github.com/brandur/wanikaniapi/wktesting"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	// Has an unknown field in the subject's data, an unknown field in a
+	// reading, and is missing `level`.
+	body := []byte(`{
+		"id": 440,
+		"object": "kanji",
+		"url": "https://api.wanikani.com/v2/subjects/440",
+		"data_updated_at": "2018-03-29T23:14:30.805034Z",
+		"data": {
+			"amalgamation_subject_ids": [],
+			"auxiliary_meanings": [],
+			"characters": "一",
+			"component_subject_ids": [],
+			"created_at": "2012-02-27T19:55:19.000000Z",
+			"document_url": "https://www.wanikani.com/kanji/%E4%B8%80",
+			"hidden_at": null,
+			"lesson_position": 2,
+			"meaning_hint": null,
+			"meaning_mnemonic": "",
+			"meanings": [],
+			"new_field": true,
+			"reading_hint": null,
+			"reading_mnemonic": "",
+			"readings": [
+				{"type": "onyomi", "primary": true, "accepted_answer": true, "reading": "いち", "romaji": "ichi"}
+			],
+			"slug": "一",
+			"spaced_repetition_system_id": 1,
+			"visually_similar_subject_ids": []
+		}
+	}`)
+
+	t.Run("OnDrift", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.StrictDecoding = true
+
+		var issues []*wanikaniapi.DriftIssue
+		client.OnDrift = func(issue *wanikaniapi.DriftIssue) {
+			issues = append(issues, issue)
+		}
+
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: body},
+		}
+
+		subject, err := client.SubjectGet(&wanikaniapi.SubjectGetParams{ID: wanikaniapi.ID(440)})
+		assert.NoError(t, err)
+		assert.Equal(t, "一", subject.KanjiData.Characters)
+
+		assert.Equal(t, []*wanikaniapi.DriftIssue{
+			{Kind: wanikaniapi.DriftKindUnknownField, Path: "data.new_field", RequestPath: "/v2/subjects/440"},
+			{Kind: wanikaniapi.DriftKindUnknownField, Path: "data.readings[0].romaji", RequestPath: "/v2/subjects/440"},
+			{Kind: wanikaniapi.DriftKindMissingField, Path: "data.level", RequestPath: "/v2/subjects/440"},
+		}, issues)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.StrictDecoding = true
+
+		var issues []*wanikaniapi.DriftIssue
+		client.OnDrift = func(issue *wanikaniapi.DriftIssue) {
+			issues = append(issues, issue)
+		}
+
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: []byte(`{
+				"object": "collection",
+				"url": "https://api.wanikani.com/v2/subjects",
+				"pages": {"per_page": 1000, "next_url": null, "previous_url": null},
+				"total_count": 1,
+				"data_updated_at": "2018-04-09T18:08:59.946969Z",
+				"new_page_field": 1,
+				"data": [` + string(body) + `]
+			}`)},
+		}
+
+		_, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{},
+			func(*wanikaniapi.Subject) error { return nil })
+		assert.NoError(t, err)
+
+		assert.Equal(t, []*wanikaniapi.DriftIssue{
+			{Kind: wanikaniapi.DriftKindUnknownField, Path: "data[0].data.new_field", RequestPath: "/v2/subjects"},
+			{Kind: wanikaniapi.DriftKindUnknownField, Path: "data[0].data.readings[0].romaji", RequestPath: "/v2/subjects"},
+			{Kind: wanikaniapi.DriftKindMissingField, Path: "data[0].data.level", RequestPath: "/v2/subjects"},
+			{Kind: wanikaniapi.DriftKindUnknownField, Path: "new_page_field", RequestPath: "/v2/subjects"},
+		}, issues)
+	})
+
+	t.Run("Off", func(t *testing.T) {
+		client := wktesting.LocalClient()
+		client.OnDrift = func(issue *wanikaniapi.DriftIssue) {
+			t.Errorf("unexpected drift issue: %v", issue)
+		}
+
+		client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+			{StatusCode: http.StatusOK, Body: body},
+		}
+
+		_, err := client.SubjectGet(&wanikaniapi.SubjectGetParams{ID: wanikaniapi.ID(440)})
+		assert.NoError(t, err)
+	})
+}
+
+func TestStrictDecodingMissingID(t *testing.T) {
+	client := wktesting.LocalClient()
+	client.StrictDecoding = true
+
+	var issues []*wanikaniapi.DriftIssue
+	client.OnDrift = func(issue *wanikaniapi.DriftIssue) {
+		issues = append(issues, issue)
+	}
+
+	// Unlike users and summaries, voice actors always have an ID, so one
+	// that's missing is reported.
+	client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "voice_actor",
+			"url": "https://api.wanikani.com/v2/voice_actors/1",
+			"data_updated_at": "2020-11-20T00:00:00.000000Z",
+			"data": {
+				"name": "Kenichi",
+				"gender": "male",
+				"description": "Tokyo accent"
+			}
+		}`)},
+	}
+
+	_, err := client.VoiceActorGet(&wanikaniapi.VoiceActorGetParams{ID: wanikaniapi.ID(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, []*wanikaniapi.DriftIssue{
+		{Kind: wanikaniapi.DriftKindMissingField, Path: "id", RequestPath: "/v2/voice_actors/1"},
+	}, issues)
+}
+
+func TestStrictDecodingPointerEmbedded(t *testing.T) {
+	assert.Equal(t,
+		[]string{"data_updated_at", "extra", "id", "object", "url"},
+		wanikaniapi.JSONFieldNames(&pointerEmbeddedObject{}))
+
+	issues := wanikaniapi.CheckDrift(&pointerEmbeddedObject{}, []byte(`{
+		"id": 1,
+		"object": "report",
+		"url": "https://api.wanikani.com/v2/summary",
+		"data_updated_at": "2018-04-11T21:00:00.000000Z",
+		"extra": "value"
+	}`))
+	assert.Empty(t, issues)
+
+	issues = wanikaniapi.CheckDrift(&pointerEmbeddedObject{}, []byte(`{
+		"id": 1,
+		"object": "report",
+		"data_updated_at": "2018-04-11T21:00:00.000000Z",
+		"extra": "value",
+		"new_field": true
+	}`))
+	assert.Equal(t, []*wanikaniapi.DriftIssue{
+		{Kind: wanikaniapi.DriftKindUnknownField, Path: "new_field"},
+		{Kind: wanikaniapi.DriftKindMissingField, Path: "url"},
+	}, issues)
+}
+
+func TestDriftIssueString(t *testing.T) {
+	issue := &wanikaniapi.DriftIssue{
+		Kind:        wanikaniapi.DriftKindUnknownField,
+		Path:        "data.new_field",
+		RequestPath: "/v2/subjects/440",
+	}
+	assert.Equal(t, "unknown_field: data.new_field (request: /v2/subjects/440)", issue.String())
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// pointerEmbeddedObject embeds Object by pointer, whose fields encoding/json
+// flattens in the same way as if it were embedded by value.
+type pointerEmbeddedObject struct {
+	*wanikaniapi.Object
+	Extra string `json:"extra"`
+}
@@ -0,0 +1,44 @@
+package wanikaniapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+//
+// Exports internals for use in tests in package wanikaniapi_test.
+//
+
+// CheckDrift checks raw JSON against the type of v in the same way as strict
+// decoding mode and returns the issues found.
+func CheckDrift(v interface{}, raw []byte) []*DriftIssue {
+	var issues []*DriftIssue
+	drift := &driftChecker{onIssue: func(issue *DriftIssue) {
+		issues = append(issues, issue)
+	}}
+	drift.check("", reflect.TypeOf(v), json.RawMessage(raw))
+	return issues
+}
+
+// JSONFieldNames returns the sorted names of the JSON fields of the struct
+// type of v.
+func JSONFieldNames(v interface{}) []string {
+	typ := reflect.TypeOf(v)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var names []string
+	for name := range jsonFields(typ) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RetainRaw sets Object.Raw on objects in v as the client does with
+// ClientConfig.RetainRaw.
+func RetainRaw(v interface{}, raw []byte) {
+	retainRaw(reflect.ValueOf(v), json.RawMessage(raw))
+}
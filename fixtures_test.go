@@ -0,0 +1,106 @@
+package wanikaniapi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// fixtureAssignment returns an assignment that was unlocked and started at
+// startedAt.
+func fixtureAssignment(subjectID wanikaniapi.WKID, stage int, startedAt, availableAt, passedAt *time.Time) *wanikaniapi.Assignment {
+	return &wanikaniapi.Assignment{Data: &wanikaniapi.AssignmentData{
+		AvailableAt: availableAt,
+		PassedAt:    passedAt,
+		SRSStage:    stage,
+		StartedAt:   startedAt,
+		SubjectID:   subjectID,
+		UnlockedAt:  startedAt,
+	}}
+}
+
+func fixtureKanji(id, srsID wanikaniapi.WKID, level int, componentIDs ...wanikaniapi.WKID) *wanikaniapi.Subject {
+	subject := fixtureSubject(id, wanikaniapi.ObjectTypeKanji,
+		wanikaniapi.SubjectCommonData{Level: level, SpacedRepetitionSystemID: srsID})
+	subject.KanjiData.ComponentSubjectIDs = componentIDs
+	return subject
+}
+
+func fixtureLevelProgression(level int, unlockedAt time.Time, passedAt, abandonedAt *time.Time) *wanikaniapi.LevelProgression {
+	return &wanikaniapi.LevelProgression{Data: &wanikaniapi.LevelProgressionData{
+		AbandonedAt: abandonedAt,
+		CreatedAt:   unlockedAt,
+		Level:       level,
+		PassedAt:    passedAt,
+		UnlockedAt:  &unlockedAt,
+	}}
+}
+
+func fixtureRadical(id, srsID wanikaniapi.WKID, level int) *wanikaniapi.Subject {
+	return fixtureSubject(id, wanikaniapi.ObjectTypeRadical,
+		wanikaniapi.SubjectCommonData{Level: level, SpacedRepetitionSystemID: srsID})
+}
+
+// fixtureReview returns a review starting at the given SRS stage that moves
+// up a stage if passed. A failed review has one incorrect reading answer.
+func fixtureReview(subjectID, srsID wanikaniapi.WKID, stage int, passed bool, createdAt time.Time) *wanikaniapi.Review {
+	review := &wanikaniapi.Review{Data: &wanikaniapi.ReviewData{
+		CreatedAt:                createdAt,
+		EndingSRSStage:           stage + 1,
+		SpacedRepetitionSystemID: srsID,
+		StartingSRSStage:         stage,
+		SubjectID:                subjectID,
+	}}
+	if !passed {
+		review.Data.EndingSRSStage = stage
+		review.Data.IncorrectReadingAnswers = 1
+	}
+	return review
+}
+
+// fixtureSubject returns a subject of the given type with its type's data set
+// to common.
+func fixtureSubject(id wanikaniapi.WKID, objectType wanikaniapi.WKObjectType, common wanikaniapi.SubjectCommonData) *wanikaniapi.Subject {
+	subject := &wanikaniapi.Subject{Object: wanikaniapi.Object{ID: id, ObjectType: objectType}}
+
+	switch objectType {
+	case wanikaniapi.ObjectTypeKanaVocabulary:
+		subject.KanaVocabularyData = &wanikaniapi.SubjectKanaVocabularyData{SubjectCommonData: common}
+	case wanikaniapi.ObjectTypeKanji:
+		subject.KanjiData = &wanikaniapi.SubjectKanjiData{SubjectCommonData: common}
+	case wanikaniapi.ObjectTypeRadical:
+		subject.RadicalData = &wanikaniapi.SubjectRadicalData{SubjectCommonData: common}
+	case wanikaniapi.ObjectTypeVocabulary:
+		subject.VocabularyData = &wanikaniapi.SubjectVocabularyData{SubjectCommonData: common}
+	}
+
+	return subject
+}
+
+func mustLoadSRS(t *testing.T, fixture string) *wanikaniapi.SpacedRepetitionSystem {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+
+	srs := &wanikaniapi.SpacedRepetitionSystem{}
+	assert.NoError(t, json.Unmarshal(data, srs))
+	return srs
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,373 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// ForecastReviews projects how many reviews become available each hour and
+// each day over the coming days.
+//
+// Reviews are assumed to be done as soon as they become available, and each
+// one is rescheduled with the stage it moves to (see
+// SpacedRepetitionSystemData.ScheduleReview), so that reviews keep cascading
+// through later stages within the forecast. With ReviewForecastParams.Accuracy,
+// a review splits into a fraction answered correctly and a fraction answered
+// incorrectly, which is why counts are fractional.
+func ForecastReviews(params *ReviewForecastParams) (*ReviewForecast, error) {
+	if params.Start.IsZero() {
+		return nil, fmt.Errorf("ReviewForecastParams.Start must be set")
+	}
+	if params.Days < 1 {
+		return nil, fmt.Errorf("ReviewForecastParams.Days must be at least 1")
+	}
+
+	stages := make([]int, 0, len(params.Accuracy))
+	for stage := range params.Accuracy {
+		stages = append(stages, stage)
+	}
+	sort.Ints(stages)
+
+	for _, stage := range stages {
+		// Written so that NaN fails too.
+		if accuracy := params.Accuracy[stage]; !(accuracy >= 0 && accuracy <= 1) {
+			return nil, fmt.Errorf("ReviewForecastParams.Accuracy of stage %v must be from 0 to 1, but was %v",
+				stage, accuracy)
+		}
+	}
+
+	systems := newSubjectSystems(params.Subjects, params.SpacedRepetitionSystems)
+
+	loc := params.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	startHour := params.Start.Truncate(time.Hour)
+	localStart := params.Start.In(loc)
+
+	var dayStarts []time.Time
+	for i := 0; i <= params.Days; i++ {
+		dayStarts = append(dayStarts,
+			time.Date(localStart.Year(), localStart.Month(), localStart.Day()+i, 0, 0, 0, 0, loc))
+	}
+	end := dayStarts[len(dayStarts)-1]
+
+	forecast := &ReviewForecast{}
+	queue := newForecastQueue()
+
+	for _, assignment := range params.Assignments {
+		data := assignment.Data
+		if data == nil || data.Hidden || data.AvailableAt == nil {
+			continue
+		}
+
+		srs, err := systems.forSubject(data.SubjectID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Assignments in the unlocking stage are in lessons and those in the
+		// burning stage are done, so neither have reviews.
+		if data.SRSStage < srs.Data.StartingStagePosition || data.SRSStage >= srs.Data.BurningStagePosition {
+			continue
+		}
+
+		if !data.AvailableAt.After(params.Start) {
+			forecast.AvailableNow++
+		}
+
+		queue.add(data.AvailableAt.Truncate(time.Hour), srs.ID, data.SRSStage, 1)
+	}
+
+	for hour := startHour; hour.Before(end); hour = hour.Add(time.Hour) {
+		point := &ReviewForecastPoint{Time: hour.In(loc), ByGroup: make(map[AssignmentStageGroup]float64)}
+
+		// Reviews that became available before the start are available now,
+		// and are reviewed in the first hour.
+		var entries []*forecastEntry
+		if hour.Equal(startHour) {
+			entries = queue.popUntil(hour)
+		} else {
+			entries = queue.pop(hour)
+		}
+
+		for _, entry := range entries {
+			srs := systems.byID[entry.srsID]
+
+			state, err := srs.Data.StageState(entry.stage)
+			if err != nil {
+				return nil, err
+			}
+
+			point.Count += entry.weight
+			point.ByGroup[state.Group] += entry.weight
+
+			accuracy := 1.0
+			if a, ok := params.Accuracy[entry.stage]; ok {
+				accuracy = a
+			}
+
+			for _, outcome := range []struct {
+				numIncorrect int
+				weight       float64
+			}{
+				{0, entry.weight * accuracy},
+				{1, entry.weight * (1 - accuracy)},
+			} {
+				if outcome.weight < forecastMinWeight {
+					continue
+				}
+
+				schedule, err := srs.Data.ScheduleReview(entry.stage, outcome.numIncorrect, hour)
+				if err != nil {
+					return nil, err
+				}
+
+				if schedule.AvailableAt != nil && schedule.AvailableAt.Before(end) {
+					queue.add(*schedule.AvailableAt, entry.srsID, schedule.Stage, outcome.weight)
+				}
+			}
+		}
+
+		forecast.Hourly = append(forecast.Hourly, point)
+	}
+
+	// Roll hours up into days in the user's time zone.
+	cumulative := 0.0
+	hourIndex := 0
+	for i, dayStart := range dayStarts[:len(dayStarts)-1] {
+		day := &ReviewForecastPoint{Time: dayStart, ByGroup: make(map[AssignmentStageGroup]float64)}
+
+		for ; hourIndex < len(forecast.Hourly) && forecast.Hourly[hourIndex].Time.Before(dayStarts[i+1]); hourIndex++ {
+			hour := forecast.Hourly[hourIndex]
+
+			cumulative += hour.Count
+			hour.Cumulative = cumulative
+
+			day.Count += hour.Count
+			for group, count := range hour.ByGroup {
+				day.ByGroup[group] += count
+			}
+		}
+
+		day.Cumulative = cumulative
+		forecast.Daily = append(forecast.Daily, day)
+	}
+
+	return forecast, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// ReviewForecast is a projection of upcoming reviews. See ForecastReviews.
+type ReviewForecast struct {
+	// AvailableNow is the number of reviews that were already available at the
+	// start of the forecast. They're included in the first hour.
+	AvailableNow float64
+
+	// Daily are the reviews that become available each day in the forecast's
+	// location, starting with the day containing the forecast's start.
+	Daily []*ReviewForecastPoint
+
+	// Hourly are the reviews that become available each hour, starting with
+	// the hour containing the forecast's start.
+	Hourly []*ReviewForecastPoint
+}
+
+// ReviewForecastParams are parameters for ForecastReviews.
+type ReviewForecastParams struct {
+	// Accuracy is the expected fraction of reviews answered correctly, from
+	// zero to one, keyed by the SRS stage position of the review. Stages
+	// without an accuracy are assumed to be answered correctly every time.
+	// Incorrectly answered reviews are assumed to have one incorrect answer.
+	// Accuracies outside of zero to one are an error.
+	Accuracy map[int]float64
+
+	// Assignments are the user's assignments.
+	Assignments []*Assignment
+
+	// Days is the number of days to forecast, including the day containing
+	// Start.
+	Days int
+
+	// Location is the user's time zone, which determines the boundaries of
+	// days. Defaults to UTC.
+	Location *time.Location
+
+	// SpacedRepetitionSystems are the spaced repetition systems of Subjects.
+	SpacedRepetitionSystems []*SpacedRepetitionSystem
+
+	// Start is the time that the forecast starts from, usually the current
+	// time.
+	Start time.Time
+
+	// Subjects are the subjects of Assignments, which are needed to find
+	// their spaced repetition systems.
+	Subjects []*Subject
+}
+
+// ReviewForecastPoint is the number of reviews that become available in one
+// hour or day of a ReviewForecast.
+type ReviewForecastPoint struct {
+	// ByGroup is Count broken down by the stage group of the reviews like
+	// AssignmentStageGroupApprentice.
+	ByGroup map[AssignmentStageGroup]float64
+
+	// Count is the number of reviews that become available.
+	Count float64
+
+	// Cumulative is the number of reviews that have become available from
+	// the start of the forecast through the end of this point.
+	Cumulative float64
+
+	// Time is the start of the hour or day in the forecast's location.
+	Time time.Time
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// forecastMinWeight is the smallest fraction of a review that's followed
+// through later stages. Smaller fractions are dropped so that a forecast with
+// accuracies doesn't track vanishingly unlikely paths.
+const forecastMinWeight = 1e-6
+
+type forecastEntry struct {
+	srsID  WKID
+	stage  int
+	weight float64
+}
+
+type forecastKey struct {
+	hour  int64
+	srsID WKID
+	stage int
+}
+
+// forecastQueue holds reviews waiting to become available, merging those of
+// the same stage and system that become available in the same hour so that
+// the number of entries stays bounded.
+type forecastQueue struct {
+	entries map[forecastKey]*forecastEntry
+}
+
+func newForecastQueue() *forecastQueue {
+	return &forecastQueue{entries: make(map[forecastKey]*forecastEntry)}
+}
+
+func (q *forecastQueue) add(hour time.Time, srsID WKID, stage int, weight float64) {
+	key := forecastKey{hour: hour.Unix(), srsID: srsID, stage: stage}
+	if entry, ok := q.entries[key]; ok {
+		entry.weight += weight
+		return
+	}
+	q.entries[key] = &forecastEntry{srsID: srsID, stage: stage, weight: weight}
+}
+
+// pop removes and returns entries for the given hour.
+func (q *forecastQueue) pop(hour time.Time) []*forecastEntry {
+	return q.popWhere(func(key forecastKey) bool { return key.hour == hour.Unix() })
+}
+
+// popUntil removes and returns entries for the given hour or earlier.
+func (q *forecastQueue) popUntil(hour time.Time) []*forecastEntry {
+	return q.popWhere(func(key forecastKey) bool { return key.hour <= hour.Unix() })
+}
+
+// popWhere removes and returns matching entries in a stable order so that
+// sums of fractional weights are deterministic.
+func (q *forecastQueue) popWhere(match func(forecastKey) bool) []*forecastEntry {
+	var keys []forecastKey
+	for key := range q.entries {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.hour != b.hour {
+			return a.hour < b.hour
+		}
+		if a.srsID != b.srsID {
+			return a.srsID < b.srsID
+		}
+		return a.stage < b.stage
+	})
+
+	entries := make([]*forecastEntry, len(keys))
+	for i, key := range keys {
+		entries[i] = q.entries[key]
+		delete(q.entries, key)
+	}
+
+	return entries
+}
+
+// subjectSystems finds the spaced repetition systems of subjects.
+type subjectSystems struct {
+	byID        map[WKID]*SpacedRepetitionSystem
+	bySubjectID map[WKID]WKID
+}
+
+func newSubjectSystems(subjects []*Subject, systems []*SpacedRepetitionSystem) *subjectSystems {
+	s := &subjectSystems{
+		byID:        make(map[WKID]*SpacedRepetitionSystem, len(systems)),
+		bySubjectID: make(map[WKID]WKID, len(subjects)),
+	}
+
+	for _, srs := range systems {
+		s.byID[srs.ID] = srs
+	}
+
+	for _, subject := range subjects {
+		common := subject.Common()
+		if common == nil {
+			continue
+		}
+		s.bySubjectID[subject.ID] = common.SpacedRepetitionSystemID
+	}
+
+	return s
+}
+
+func (s *subjectSystems) forSubject(subjectID WKID) (*SpacedRepetitionSystem, error) {
+	srsID, ok := s.bySubjectID[subjectID]
+	if !ok {
+		return nil, fmt.Errorf("subject %v not found", subjectID)
+	}
+
+	srs, ok := s.byID[srsID]
+	if !ok {
+		return nil, fmt.Errorf("spaced repetition system %v of subject %v not found", srsID, subjectID)
+	}
+
+	return srs, nil
+}
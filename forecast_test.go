@@ -0,0 +1,169 @@
+package wanikaniapi_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestForecastReviews(t *testing.T) {
+	srsDefault := mustLoadSRS(t, "spaced_repetition_system.json")
+	srsAccelerated := mustLoadSRS(t, "spaced_repetition_system_accelerated.json")
+
+	subjects := []*wanikaniapi.Subject{
+		fixtureKanji(1, srsDefault.ID, 1),
+		fixtureKanji(2, srsDefault.ID, 1),
+		fixtureKanji(3, srsAccelerated.ID, 1),
+		fixtureKanji(4, srsDefault.ID, 1),
+	}
+
+	start := time.Date(2021, 3, 14, 10, 20, 0, 0, time.UTC)
+	hour := func(day, hour int) time.Time {
+		return time.Date(2021, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	params := func(assignments ...*wanikaniapi.Assignment) *wanikaniapi.ReviewForecastParams {
+		return &wanikaniapi.ReviewForecastParams{
+			Assignments:             assignments,
+			Days:                    3,
+			SpacedRepetitionSystems: []*wanikaniapi.SpacedRepetitionSystem{srsDefault, srsAccelerated},
+			Start:                   start,
+			Subjects:                subjects,
+		}
+	}
+
+	t.Run("AllCorrect", func(t *testing.T) {
+		forecast, err := wanikaniapi.ForecastReviews(params(
+			// Available now, and cascades through stages 2 and 3 within the
+			// forecast. Stage 4 is beyond it.
+			fixtureAssignment(1, 1, nil, timePtr(hour(14, 9)), nil),
+
+			// Becomes available later today, and its next review at stage 5
+			// is beyond the forecast.
+			fixtureAssignment(2, 4, nil, timePtr(hour(14, 13)), nil),
+
+			// In the accelerated system, so its cascade is faster: stage 2
+			// at 14:00, stage 3 at 22:00, and stage 4 at 21:00 tomorrow.
+			fixtureAssignment(3, 1, nil, timePtr(hour(14, 10)), nil),
+
+			// Not reviewable: hidden, in lessons, and burned.
+			&wanikaniapi.Assignment{Data: &wanikaniapi.AssignmentData{
+				AvailableAt: timePtr(hour(14, 11)), Hidden: true, SRSStage: 1, SubjectID: 4,
+			}},
+			fixtureAssignment(4, 0, nil, timePtr(hour(14, 11)), nil),
+			fixtureAssignment(4, 9, nil, timePtr(hour(14, 11)), nil),
+		))
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2.0, forecast.AvailableNow)
+
+		// From 10:00 on the first day through the end of the third.
+		assert.Equal(t, 14+24+24, len(forecast.Hourly))
+		assert.Equal(t, hour(14, 10), forecast.Hourly[0].Time)
+
+		counts := make(map[time.Time]float64)
+		for _, point := range forecast.Hourly {
+			if point.Count != 0 {
+				counts[point.Time] = point.Count
+			}
+		}
+		assert.Equal(t, map[time.Time]float64{
+			hour(14, 10): 2, // subjects 1 and 3, available now
+			hour(14, 13): 1, // subject 2
+			hour(14, 14): 1, // subject 3 at stage 2
+			hour(14, 18): 1, // subject 1 at stage 2
+			hour(14, 22): 1, // subject 3 at stage 3
+			hour(15, 17): 1, // subject 1 at stage 3
+			hour(15, 21): 1, // subject 3 at stage 4
+		}, counts)
+
+		assert.Equal(t, 3, len(forecast.Daily))
+		assert.Equal(t, hour(14, 0), forecast.Daily[0].Time)
+		assert.Equal(t, 6.0, forecast.Daily[0].Count)
+		assert.Equal(t, 6.0, forecast.Daily[0].ByGroup[wanikaniapi.AssignmentStageGroupApprentice])
+		assert.Equal(t, 2.0, forecast.Daily[1].Count)
+		assert.Equal(t, 8.0, forecast.Daily[1].Cumulative)
+		assert.Equal(t, 0.0, forecast.Daily[2].Count)
+		assert.Equal(t, 8.0, forecast.Daily[2].Cumulative)
+
+		last := forecast.Hourly[len(forecast.Hourly)-1]
+		assert.Equal(t, hour(16, 23), last.Time)
+		assert.Equal(t, 8.0, last.Cumulative)
+	})
+
+	t.Run("Accuracy", func(t *testing.T) {
+		p := params(fixtureAssignment(1, 1, nil, timePtr(hour(14, 9)), nil))
+		p.Accuracy = map[int]float64{1: 0.5}
+
+		forecast, err := wanikaniapi.ForecastReviews(p)
+		assert.NoError(t, err)
+
+		counts := make(map[time.Time]float64)
+		for _, point := range forecast.Hourly {
+			counts[point.Time] = point.Count
+		}
+
+		// Half stays at stage 1 and comes back after 4 hours, and half moves
+		// to stage 2 and comes back after 8. At 18:00, the half at stage 2
+		// meets the quarter that stayed at stage 1 twice.
+		assert.Equal(t, 1.0, counts[hour(14, 10)])
+		assert.Equal(t, 0.5, counts[hour(14, 14)])
+		assert.Equal(t, 0.5+0.25, counts[hour(14, 18)])
+		assert.Equal(t, 0.25+0.125, counts[hour(14, 22)])
+
+		point := forecast.Hourly[8]
+		assert.Equal(t, hour(14, 18), point.Time)
+		assert.Equal(t, 0.75, point.ByGroup[wanikaniapi.AssignmentStageGroupApprentice])
+	})
+
+	t.Run("Location", func(t *testing.T) {
+		jst := time.FixedZone("JST", 9*60*60)
+
+		p := params(
+			fixtureAssignment(2, 4, nil, timePtr(hour(14, 14)), nil), // 23:00 JST on the 14th
+			fixtureAssignment(4, 4, nil, timePtr(hour(14, 16)), nil), // 01:00 JST on the 15th
+		)
+		p.Days = 2
+		p.Location = jst
+
+		forecast, err := wanikaniapi.ForecastReviews(p)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, len(forecast.Daily))
+		assert.Equal(t, time.Date(2021, 3, 14, 0, 0, 0, 0, jst), forecast.Daily[0].Time)
+		assert.Equal(t, 1.0, forecast.Daily[0].Count)
+		assert.Equal(t, time.Date(2021, 3, 15, 0, 0, 0, 0, jst), forecast.Daily[1].Time)
+		assert.Equal(t, 1.0, forecast.Daily[1].Count)
+
+		// Hours run through the end of the last day in JST.
+		assert.Equal(t, time.Date(2021, 3, 15, 23, 0, 0, 0, jst),
+			forecast.Hourly[len(forecast.Hourly)-1].Time)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := wanikaniapi.ForecastReviews(params(fixtureAssignment(99, 1, nil, timePtr(hour(14, 9)), nil)))
+		assert.EqualError(t, err, "subject 99 not found")
+
+		p := params(fixtureAssignment(1, 1, nil, timePtr(hour(14, 9)), nil))
+		p.SpacedRepetitionSystems = nil
+		_, err = wanikaniapi.ForecastReviews(p)
+		assert.EqualError(t, err, "spaced repetition system 1 of subject 1 not found")
+
+		p = params()
+		p.Days = 0
+		_, err = wanikaniapi.ForecastReviews(p)
+		assert.EqualError(t, err, "ReviewForecastParams.Days must be at least 1")
+
+		p = params()
+		p.Accuracy = map[int]float64{1: 0.9, 2: 1.5, 3: -0.1}
+		_, err = wanikaniapi.ForecastReviews(p)
+		assert.EqualError(t, err, "ReviewForecastParams.Accuracy of stage 2 must be from 0 to 1, but was 1.5")
+
+		p.Accuracy = map[int]float64{3: math.NaN()}
+		_, err = wanikaniapi.ForecastReviews(p)
+		assert.EqualError(t, err, "ReviewForecastParams.Accuracy of stage 3 must be from 0 to 1, but was NaN")
+	})
+}
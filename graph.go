@@ -0,0 +1,390 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"sort"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewSubjectGraph builds a graph of the relationships between the given
+// subjects.
+//
+// Relationships are read from both sides, so a kanji is a component of a
+// vocabulary if either the vocabulary lists it in ComponentSubjectIDs or the
+// kanji lists the vocabulary in AmalgamationSubjectIDs. Likewise, visual
+// similarity is treated as symmetric. References to subjects that aren't in
+// the graph are ignored by queries and reported by DanglingReferences.
+func NewSubjectGraph(subjects []*Subject) *SubjectGraph {
+	g := &SubjectGraph{
+		amalgamations:   make(map[WKID]map[WKID]struct{}),
+		components:      make(map[WKID]map[WKID]struct{}),
+		subjects:        make(map[WKID]*Subject, len(subjects)),
+		visuallySimilar: make(map[WKID]map[WKID]struct{}),
+	}
+
+	for _, subject := range subjects {
+		g.subjects[subject.ID] = subject
+	}
+
+	for _, subject := range subjects {
+		rels := subjectRelationships(subject)
+
+		for _, id := range rels.amalgamationIDs {
+			g.addReference(subject.ID, "amalgamation_subject_ids", id)
+			addGraphEdge(g.amalgamations, subject.ID, id)
+			addGraphEdge(g.components, id, subject.ID)
+		}
+
+		for _, id := range rels.componentIDs {
+			g.addReference(subject.ID, "component_subject_ids", id)
+			addGraphEdge(g.components, subject.ID, id)
+			addGraphEdge(g.amalgamations, id, subject.ID)
+		}
+
+		for _, id := range rels.visuallySimilarIDs {
+			g.addReference(subject.ID, "visually_similar_subject_ids", id)
+			addGraphEdge(g.visuallySimilar, subject.ID, id)
+			addGraphEdge(g.visuallySimilar, id, subject.ID)
+		}
+	}
+
+	sort.Slice(g.dangling, func(i, j int) bool {
+		a, b := g.dangling[i], g.dangling[j]
+		if a.SubjectID != b.SubjectID {
+			return a.SubjectID < b.SubjectID
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.MissingID < b.MissingID
+	})
+
+	return g
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// SubjectGraph is a graph of the relationships between subjects: radicals are
+// components of kanji, kanji are components of vocabulary, and the reverse
+// relationship is amalgamation. Kanji may also be visually similar to other
+// kanji.
+//
+// Queries that return subjects return them sorted by level, then by type
+// (radicals, then kanji, then vocabulary), then by ID. Queries take optional
+// object types like ObjectTypeRadical, and if any are given, only subjects of
+// those types are returned.
+//
+// A graph isn't modified after it's built, so it's safe for concurrent use.
+type SubjectGraph struct {
+	amalgamations   map[WKID]map[WKID]struct{}
+	components      map[WKID]map[WKID]struct{}
+	dangling        []*SubjectGraphDanglingReference
+	subjects        map[WKID]*Subject
+	visuallySimilar map[WKID]map[WKID]struct{}
+}
+
+// AllAmalgamations returns the subjects that the given subject is a component
+// of, directly or transitively. For example, all the vocabulary that use a
+// radical through one of their kanji.
+func (g *SubjectGraph) AllAmalgamations(id WKID, types ...WKObjectType) []*Subject {
+	return g.sortedSubjects(g.walk(g.amalgamations, id), types)
+}
+
+// AllComponents returns the subjects that make up the given subject, directly
+// or transitively. For example, all the radicals of a vocabulary's kanji.
+func (g *SubjectGraph) AllComponents(id WKID, types ...WKObjectType) []*Subject {
+	return g.sortedSubjects(g.walk(g.components, id), types)
+}
+
+// Amalgamations returns the subjects that the given subject is directly a
+// component of. For example, the vocabulary that use a kanji.
+func (g *SubjectGraph) Amalgamations(id WKID, types ...WKObjectType) []*Subject {
+	return g.sortedSubjects(g.amalgamations[id], types)
+}
+
+// Components returns the subjects that directly make up the given subject.
+// For example, the kanji of a vocabulary.
+func (g *SubjectGraph) Components(id WKID, types ...WKObjectType) []*Subject {
+	return g.sortedSubjects(g.components[id], types)
+}
+
+// DanglingReferences returns references from subjects in the graph to
+// subjects that aren't in it. These are expected when a graph is built from
+// only some subjects, but otherwise indicate missing data.
+func (g *SubjectGraph) DanglingReferences() []*SubjectGraphDanglingReference {
+	return g.dangling
+}
+
+// DependencyOrder returns the subjects with the given IDs ordered so that
+// every subject comes after all of its components, including components that
+// are connected only through subjects that weren't given. Otherwise, subjects
+// keep the graph's usual sort order. If no IDs are given, all subjects in the
+// graph are ordered. IDs of subjects that aren't in the graph are ignored.
+//
+// Returns an error if components form a cycle, which shouldn't happen with
+// data from WaniKani.
+func (g *SubjectGraph) DependencyOrder(ids ...WKID) ([]*Subject, error) {
+	wanted := make(map[WKID]struct{}, len(ids))
+	for _, id := range ids {
+		wanted[id] = struct{}{}
+	}
+
+	roots := wanted
+	if len(ids) == 0 {
+		roots = make(map[WKID]struct{}, len(g.subjects))
+		for id := range g.subjects {
+			roots[id] = struct{}{}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	states := make(map[WKID]int)
+	var ordered []*Subject
+
+	var visit func(subject *Subject) error
+	visit = func(subject *Subject) error {
+		switch states[subject.ID] {
+		case visiting:
+			return fmt.Errorf("dependency cycle involving subject %v", subject.ID)
+		case visited:
+			return nil
+		}
+
+		states[subject.ID] = visiting
+		for _, component := range g.sortedSubjects(g.components[subject.ID], nil) {
+			if err := visit(component); err != nil {
+				return err
+			}
+		}
+		states[subject.ID] = visited
+
+		if _, ok := wanted[subject.ID]; ok || len(ids) == 0 {
+			ordered = append(ordered, subject)
+		}
+		return nil
+	}
+
+	for _, subject := range g.sortedSubjects(roots, nil) {
+		if err := visit(subject); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// Subject returns the subject with the given ID, or nil if it's not in the
+// graph.
+func (g *SubjectGraph) Subject(id WKID) *Subject {
+	return g.subjects[id]
+}
+
+// VisuallySimilar returns the subjects that are visually similar to the
+// given subject.
+func (g *SubjectGraph) VisuallySimilar(id WKID, types ...WKObjectType) []*Subject {
+	return g.sortedSubjects(g.visuallySimilar[id], types)
+}
+
+// VisuallySimilarPairs returns each pair of subjects among those with the
+// given IDs that are visually similar to each other. Within a pair, A sorts
+// before B.
+func (g *SubjectGraph) VisuallySimilarPairs(ids []WKID) []*SubjectPair {
+	set := make(map[WKID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+
+	var pairs []*SubjectPair
+	subjects := g.sortedSubjects(set, nil)
+	for i, a := range subjects {
+		for _, b := range subjects[i+1:] {
+			if _, ok := g.visuallySimilar[a.ID][b.ID]; ok {
+				pairs = append(pairs, &SubjectPair{A: a, B: b})
+			}
+		}
+	}
+
+	return pairs
+}
+
+// SubjectGraphDanglingReference is a reference from a subject in a
+// SubjectGraph to a subject that isn't in it.
+type SubjectGraphDanglingReference struct {
+	// Field is the name of the field containing the reference like
+	// `component_subject_ids`.
+	Field string
+
+	// MissingID is the ID of the subject that isn't in the graph.
+	MissingID WKID
+
+	// SubjectID is the ID of the subject containing the reference.
+	SubjectID WKID
+}
+
+// SubjectPair is a pair of related subjects.
+type SubjectPair struct {
+	A *Subject
+	B *Subject
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// subjectTypeRanks orders subject types as they're learned.
+var subjectTypeRanks = map[WKObjectType]int{
+	ObjectTypeRadical:        0,
+	ObjectTypeKanji:          1,
+	ObjectTypeVocabulary:     2,
+	ObjectTypeKanaVocabulary: 2,
+}
+
+// subjectRelationshipIDs are the IDs of subjects related to a subject.
+type subjectRelationshipIDs struct {
+	amalgamationIDs    []WKID
+	componentIDs       []WKID
+	visuallySimilarIDs []WKID
+}
+
+func (g *SubjectGraph) addReference(subjectID WKID, field string, id WKID) {
+	if _, ok := g.subjects[id]; !ok {
+		g.dangling = append(g.dangling, &SubjectGraphDanglingReference{
+			Field:     field,
+			MissingID: id,
+			SubjectID: subjectID,
+		})
+	}
+}
+
+// sortedSubjects returns the subjects in the graph with the given IDs in the
+// graph's sort order, keeping only those of the given types if any are given.
+func (g *SubjectGraph) sortedSubjects(ids map[WKID]struct{}, types []WKObjectType) []*Subject {
+	subjects := make([]*Subject, 0, len(ids))
+	for id := range ids {
+		subject, ok := g.subjects[id]
+		if !ok || !subjectHasType(subject, types) {
+			continue
+		}
+		subjects = append(subjects, subject)
+	}
+
+	sort.Slice(subjects, func(i, j int) bool {
+		return subjectLess(subjects[i], subjects[j])
+	})
+
+	return subjects
+}
+
+// walk returns the IDs of all subjects reachable from id through edges, not
+// including id itself unless it's reachable through a cycle.
+func (g *SubjectGraph) walk(edges map[WKID]map[WKID]struct{}, id WKID) map[WKID]struct{} {
+	seen := make(map[WKID]struct{})
+	queue := []WKID{id}
+
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		for related := range edges[next] {
+			if _, ok := seen[related]; ok {
+				continue
+			}
+			seen[related] = struct{}{}
+			queue = append(queue, related)
+		}
+	}
+
+	return seen
+}
+
+func addGraphEdge(edges map[WKID]map[WKID]struct{}, from, to WKID) {
+	if edges[from] == nil {
+		edges[from] = make(map[WKID]struct{})
+	}
+	edges[from][to] = struct{}{}
+}
+
+func subjectHasType(subject *Subject, types []WKObjectType) bool {
+	if len(types) == 0 {
+		return true
+	}
+
+	for _, typ := range types {
+		if subject.ObjectType == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subjectLess orders subjects by level, then by type, then by ID.
+func subjectLess(a, b *Subject) bool {
+	if aLevel, bLevel := a.Level(), b.Level(); aLevel != bLevel {
+		return aLevel < bLevel
+	}
+
+	if aRank, bRank := subjectTypeRank(a), subjectTypeRank(b); aRank != bRank {
+		return aRank < bRank
+	}
+
+	return a.ID < b.ID
+}
+
+func subjectRelationships(subject *Subject) *subjectRelationshipIDs {
+	switch {
+	case subject.KanjiData != nil:
+		return &subjectRelationshipIDs{
+			amalgamationIDs:    subject.KanjiData.AmalgamationSubjectIDs,
+			componentIDs:       subject.KanjiData.ComponentSubjectIDs,
+			visuallySimilarIDs: subject.KanjiData.VisuallySimilarSubjectIDs,
+		}
+
+	case subject.RadicalData != nil:
+		return &subjectRelationshipIDs{
+			amalgamationIDs: subject.RadicalData.AmalgamationSubjectIDs,
+		}
+
+	case subject.VocabularyData != nil:
+		return &subjectRelationshipIDs{
+			componentIDs: subject.VocabularyData.ComponentSubjectIDs,
+		}
+	}
+
+	return &subjectRelationshipIDs{}
+}
+
+// subjectTypeRank returns a subject's position in subjectTypeRanks. Unknown
+// types come after all known ones.
+func subjectTypeRank(subject *Subject) int {
+	if rank, ok := subjectTypeRanks[subject.ObjectType]; ok {
+		return rank
+	}
+	return len(subjectTypeRanks)
+}
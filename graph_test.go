@@ -0,0 +1,173 @@
+package wanikaniapi_test
+
+import (
+	"testing"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSubjectGraph(t *testing.T) {
+	graph := wanikaniapi.NewSubjectGraph(graphSubjects())
+
+	t.Run("Subject", func(t *testing.T) {
+		assert.Equal(t, wanikaniapi.WKID(10), graph.Subject(10).ID)
+		assert.Nil(t, graph.Subject(12345))
+	})
+
+	t.Run("Components", func(t *testing.T) {
+		assert.Equal(t, []wanikaniapi.WKID{10, 11}, subjectIDs(graph.Components(100)))
+		assert.Equal(t, []wanikaniapi.WKID{1, 2}, subjectIDs(graph.Components(10)))
+
+		// Radical 3 only lists kanji 11 as an amalgamation, but is still
+		// found as a component of it.
+		assert.Equal(t, []wanikaniapi.WKID{1, 3}, subjectIDs(graph.Components(11)))
+
+		assert.Empty(t, graph.Components(1))
+		assert.Empty(t, graph.Components(12345))
+	})
+
+	t.Run("AllComponents", func(t *testing.T) {
+		assert.Equal(t, []wanikaniapi.WKID{1, 2, 3, 10, 11}, subjectIDs(graph.AllComponents(100)))
+		assert.Equal(t, []wanikaniapi.WKID{1, 2, 3},
+			subjectIDs(graph.AllComponents(100, wanikaniapi.ObjectTypeRadical)))
+	})
+
+	t.Run("Amalgamations", func(t *testing.T) {
+		assert.Equal(t, []wanikaniapi.WKID{100, 101}, subjectIDs(graph.Amalgamations(10)))
+		assert.Equal(t, []wanikaniapi.WKID{10, 11}, subjectIDs(graph.Amalgamations(1)))
+	})
+
+	t.Run("AllAmalgamations", func(t *testing.T) {
+		assert.Equal(t, []wanikaniapi.WKID{10, 11, 100, 101}, subjectIDs(graph.AllAmalgamations(1)))
+		assert.Equal(t, []wanikaniapi.WKID{100},
+			subjectIDs(graph.AllAmalgamations(3, wanikaniapi.ObjectTypeVocabulary)))
+	})
+
+	t.Run("VisuallySimilar", func(t *testing.T) {
+		assert.Equal(t, []wanikaniapi.WKID{11}, subjectIDs(graph.VisuallySimilar(10)))
+
+		// Symmetric even though only kanji 10 lists the similarity.
+		assert.Equal(t, []wanikaniapi.WKID{10}, subjectIDs(graph.VisuallySimilar(11)))
+
+		pairs := graph.VisuallySimilarPairs([]wanikaniapi.WKID{100, 11, 10, 1})
+		assert.Equal(t, 1, len(pairs))
+		assert.Equal(t, wanikaniapi.WKID(10), pairs[0].A.ID)
+		assert.Equal(t, wanikaniapi.WKID(11), pairs[0].B.ID)
+
+		assert.Empty(t, graph.VisuallySimilarPairs([]wanikaniapi.WKID{10, 100}))
+	})
+
+	t.Run("DependencyOrder", func(t *testing.T) {
+		ordered, err := graph.DependencyOrder()
+		assert.NoError(t, err)
+		assert.Equal(t, []wanikaniapi.WKID{1, 2, 3, 10, 11, 100, 101, 200, 4, 12, 300}, subjectIDs(ordered))
+
+		// Radical 4 is at a later level than the vocabulary, but is still
+		// ordered before it because the vocabulary depends on it through
+		// kanji 12.
+		ordered, err = graph.DependencyOrder(300, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []wanikaniapi.WKID{4, 300}, subjectIDs(ordered))
+
+		// Unknown IDs are ignored.
+		ordered, err = graph.DependencyOrder(12345, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []wanikaniapi.WKID{1}, subjectIDs(ordered))
+	})
+
+	t.Run("DependencyOrderCycle", func(t *testing.T) {
+		graph := wanikaniapi.NewSubjectGraph([]*wanikaniapi.Subject{
+			graphKanji(1, 1, []wanikaniapi.WKID{2}, nil),
+			graphKanji(2, 1, []wanikaniapi.WKID{1}, nil),
+		})
+
+		_, err := graph.DependencyOrder()
+		assert.EqualError(t, err, "dependency cycle involving subject 1")
+	})
+
+	t.Run("DanglingReferences", func(t *testing.T) {
+		assert.Equal(t, []*wanikaniapi.SubjectGraphDanglingReference{
+			{Field: "visually_similar_subject_ids", MissingID: 998, SubjectID: 11},
+			{Field: "component_subject_ids", MissingID: 999, SubjectID: 101},
+		}, graph.DanglingReferences())
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// graphSubjects returns a small set of related subjects:
+//
+//	radicals:   1, 2, 3 (level 1), 4 (level 5)
+//	kanji:      10 = 1 + 2, 11 = 1 + 3 (level 1), 12 = 4 (level 2)
+//	vocabulary: 100 = 10 + 11, 101 = 10 + missing 999 (level 1), 300 = 12 (level 3)
+//	kana:       200 (level 1)
+//
+// Kanji 10 and 11 are visually similar, and kanji 11 is similar to missing
+// subject 998.
+func graphSubjects() []*wanikaniapi.Subject {
+	radical := func(id wanikaniapi.WKID, level int, amalgamationIDs []wanikaniapi.WKID) *wanikaniapi.Subject {
+		return &wanikaniapi.Subject{
+			Object: wanikaniapi.Object{ID: id, ObjectType: wanikaniapi.ObjectTypeRadical},
+			RadicalData: &wanikaniapi.SubjectRadicalData{
+				SubjectCommonData:      wanikaniapi.SubjectCommonData{Level: level},
+				AmalgamationSubjectIDs: amalgamationIDs,
+			},
+		}
+	}
+	vocabulary := func(id wanikaniapi.WKID, level int, componentIDs []wanikaniapi.WKID) *wanikaniapi.Subject {
+		return &wanikaniapi.Subject{
+			Object: wanikaniapi.Object{ID: id, ObjectType: wanikaniapi.ObjectTypeVocabulary},
+			VocabularyData: &wanikaniapi.SubjectVocabularyData{
+				SubjectCommonData:   wanikaniapi.SubjectCommonData{Level: level},
+				ComponentSubjectIDs: componentIDs,
+			},
+		}
+	}
+
+	return []*wanikaniapi.Subject{
+		vocabulary(300, 3, []wanikaniapi.WKID{12}),
+		radical(1, 1, nil),
+		radical(2, 1, nil),
+		radical(3, 1, []wanikaniapi.WKID{11}),
+		radical(4, 5, nil),
+		graphKanji(10, 1, []wanikaniapi.WKID{1, 2}, []wanikaniapi.WKID{11}),
+		graphKanji(11, 1, []wanikaniapi.WKID{1}, []wanikaniapi.WKID{998}),
+		graphKanji(12, 2, []wanikaniapi.WKID{4}, nil),
+		vocabulary(100, 1, []wanikaniapi.WKID{10, 11}),
+		vocabulary(101, 1, []wanikaniapi.WKID{10, 999}),
+		{
+			Object: wanikaniapi.Object{ID: 200, ObjectType: wanikaniapi.ObjectTypeKanaVocabulary},
+			KanaVocabularyData: &wanikaniapi.SubjectKanaVocabularyData{
+				SubjectCommonData: wanikaniapi.SubjectCommonData{Level: 1},
+			},
+		},
+	}
+}
+
+func graphKanji(id wanikaniapi.WKID, level int, componentIDs, visuallySimilarIDs []wanikaniapi.WKID) *wanikaniapi.Subject {
+	return &wanikaniapi.Subject{
+		Object: wanikaniapi.Object{ID: id, ObjectType: wanikaniapi.ObjectTypeKanji},
+		KanjiData: &wanikaniapi.SubjectKanjiData{
+			SubjectCommonData:         wanikaniapi.SubjectCommonData{Level: level},
+			ComponentSubjectIDs:       componentIDs,
+			VisuallySimilarSubjectIDs: visuallySimilarIDs,
+		},
+	}
+}
+
+func subjectIDs(subjects []*wanikaniapi.Subject) []wanikaniapi.WKID {
+	ids := make([]wanikaniapi.WKID, len(subjects))
+	for i, subject := range subjects {
+		ids[i] = subject.ID
+	}
+	return ids
+}
@@ -0,0 +1,199 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// FindLeeches scores review statistics to find leeches, subjects that the
+// user keeps getting wrong, and returns them ranked from worst to best along
+// with their subjects.
+//
+// Meaning and reading are scored separately. Each part's score is its
+// incorrect rate weighted by its number of incorrect answers, so that a single
+// early miss doesn't look like a leech, and divided by its current streak
+// raised to LeechParams.StreakExponent, so that a subject that's being
+// answered correctly again falls out of the list. A subject's score is the
+// worse of its two.
+func FindLeeches(params *LeechParams) ([]*Leech, error) {
+	streakExponent := params.StreakExponent
+	if streakExponent == 0 {
+		streakExponent = LeechDefaultStreakExponent
+	}
+
+	subjects := make(map[WKID]*Subject, len(params.Subjects))
+	for _, subject := range params.Subjects {
+		subjects[subject.ID] = subject
+	}
+
+	var leeches []*Leech
+	for _, stat := range params.ReviewStatistics {
+		data := stat.Data
+		if data == nil || data.Hidden {
+			continue
+		}
+
+		if params.CriticalThreshold != 0 && data.PercentageCorrect >= params.CriticalThreshold {
+			continue
+		}
+
+		leech := &Leech{
+			Meaning:         newLeechScore(data.MeaningCorrect, data.MeaningIncorrect, data.MeaningCurrentStreak, streakExponent),
+			ReviewStatistic: stat,
+		}
+
+		// WaniKani reports a reading for radicals even though they don't have
+		// one, so it'd only ever look like a perfect score.
+		if data.SubjectType != ObjectTypeRadical {
+			leech.Reading = newLeechScore(data.ReadingCorrect, data.ReadingIncorrect, data.ReadingCurrentStreak, streakExponent)
+		}
+
+		for _, score := range []*LeechScore{leech.Meaning, leech.Reading} {
+			if score != nil && score.Score > leech.Score {
+				leech.Score = score.Score
+			}
+		}
+
+		if leech.Score == 0 || leech.Score < params.MinScore {
+			continue
+		}
+
+		subject, ok := subjects[data.SubjectID]
+		if !ok {
+			return nil, fmt.Errorf("subject %v not found", data.SubjectID)
+		}
+		leech.Subject = subject
+
+		leeches = append(leeches, leech)
+	}
+
+	sort.Slice(leeches, func(i, j int) bool {
+		if leeches[i].Score != leeches[j].Score {
+			return leeches[i].Score > leeches[j].Score
+		}
+		return leeches[i].Subject.ID < leeches[j].Subject.ID
+	})
+
+	return leeches, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LeechCriticalThreshold is the percentage correct below which WaniKani's
+// dashboard considers a subject to be in critical condition. Use it as
+// LeechParams.CriticalThreshold.
+const LeechCriticalThreshold = 75
+
+// LeechDefaultStreakExponent is the default LeechParams.StreakExponent.
+const LeechDefaultStreakExponent = 1.5
+
+// Leech is a subject that the user keeps getting wrong. See FindLeeches.
+type Leech struct {
+	// Meaning is the score of the subject's meaning.
+	Meaning *LeechScore
+
+	// Reading is the score of the subject's reading. Nil for radicals and for
+	// subjects whose reading hasn't been answered yet.
+	Reading *LeechScore
+
+	// ReviewStatistic is the review statistic that was scored.
+	ReviewStatistic *ReviewStatistic
+
+	// Score is the worse of the meaning and reading scores.
+	Score float64
+
+	// Subject is the leech's subject.
+	Subject *Subject
+}
+
+// LeechParams are parameters for FindLeeches.
+type LeechParams struct {
+	// CriticalThreshold only includes subjects whose PercentageCorrect is below
+	// it, like LeechCriticalThreshold. Zero includes subjects regardless of
+	// their percentage correct.
+	CriticalThreshold int
+
+	// MinScore only includes subjects with a score at least this high. Zero
+	// includes every subject that's been answered incorrectly.
+	MinScore float64
+
+	// ReviewStatistics are the user's review statistics.
+	ReviewStatistics []*ReviewStatistic
+
+	// StreakExponent is how strongly a current streak of correct answers
+	// reduces a score. Zero means LeechDefaultStreakExponent, so an exponent
+	// of zero can't be given; use a very small one instead to make streaks
+	// have no effect.
+	StreakExponent float64
+
+	// Subjects are the subjects of ReviewStatistics.
+	Subjects []*Subject
+}
+
+// LeechScore is the score of a subject's meaning or reading.
+type LeechScore struct {
+	// Correct is the number of correct answers.
+	Correct int
+
+	// CurrentStreak is the number of correct answers in a row.
+	CurrentStreak int
+
+	// Incorrect is the number of incorrect answers.
+	Incorrect int
+
+	// IncorrectRate is the fraction of answers that were incorrect.
+	IncorrectRate float64
+
+	// Score is the leech score. Higher is worse.
+	Score float64
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+func newLeechScore(correct, incorrect, currentStreak int, streakExponent float64) *LeechScore {
+	total := correct + incorrect
+	if total == 0 {
+		return nil
+	}
+
+	score := &LeechScore{
+		Correct:       correct,
+		CurrentStreak: currentStreak,
+		Incorrect:     incorrect,
+		IncorrectRate: float64(incorrect) / float64(total),
+	}
+
+	// Streaks of zero and one have the same effect so that a subject that was
+	// just answered incorrectly isn't divided by zero.
+	streak := math.Max(1, float64(currentStreak))
+	score.Score = score.IncorrectRate * float64(incorrect) / math.Pow(streak, streakExponent)
+
+	return score
+}
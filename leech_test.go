@@ -0,0 +1,125 @@
+package wanikaniapi_test
+
+import (
+	"testing"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestFindLeeches(t *testing.T) {
+	subjects := []*wanikaniapi.Subject{
+		fixtureKanji(1, 1, 1),
+		fixtureRadical(2, 1, 1),
+		fixtureKanji(3, 1, 1),
+		fixtureKanji(4, 1, 1),
+		fixtureKanji(5, 1, 1),
+	}
+
+	stats := []*wanikaniapi.ReviewStatistic{
+		// The reading is the problem.
+		{Data: &wanikaniapi.ReviewStatisticData{
+			MeaningCorrect: 8, MeaningIncorrect: 2, MeaningCurrentStreak: 4,
+			ReadingCorrect: 5, ReadingIncorrect: 5, ReadingCurrentStreak: 1,
+			PercentageCorrect: 65, SubjectID: 1,
+		}},
+
+		// A radical's reading is ignored even though WaniKani reports one. A
+		// current streak of zero is treated as one.
+		{Data: &wanikaniapi.ReviewStatisticData{
+			MeaningCorrect: 3, MeaningIncorrect: 6, MeaningCurrentStreak: 0,
+			ReadingCorrect: 1, ReadingCurrentStreak: 1,
+			PercentageCorrect: 33, SubjectID: 2, SubjectType: wanikaniapi.ObjectTypeRadical,
+		}},
+
+		// Missed once a long time ago.
+		{Data: &wanikaniapi.ReviewStatisticData{
+			MeaningCorrect: 10, MeaningIncorrect: 1, MeaningCurrentStreak: 9,
+			ReadingCorrect: 10, ReadingCurrentStreak: 10,
+			PercentageCorrect: 95, SubjectID: 3,
+		}},
+
+		// Never missed.
+		{Data: &wanikaniapi.ReviewStatisticData{
+			MeaningCorrect: 10, MeaningCurrentStreak: 10,
+			ReadingCorrect: 10, ReadingCurrentStreak: 10,
+			PercentageCorrect: 100, SubjectID: 4,
+		}},
+
+		// Hidden.
+		{Data: &wanikaniapi.ReviewStatisticData{
+			Hidden:         true,
+			MeaningCorrect: 1, MeaningIncorrect: 10,
+			PercentageCorrect: 9, SubjectID: 5,
+		}},
+	}
+
+	leechSubjectIDs := func(leeches []*wanikaniapi.Leech) []wanikaniapi.WKID {
+		var ids []wanikaniapi.WKID
+		for _, leech := range leeches {
+			ids = append(ids, leech.Subject.ID)
+		}
+		return ids
+	}
+
+	t.Run("All", func(t *testing.T) {
+		leeches, err := wanikaniapi.FindLeeches(&wanikaniapi.LeechParams{
+			ReviewStatistics: stats,
+			Subjects:         subjects,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []wanikaniapi.WKID{2, 1, 3}, leechSubjectIDs(leeches))
+
+		assert.Equal(t, 4.0, leeches[0].Score)
+		assert.Nil(t, leeches[0].Reading)
+
+		assert.Equal(t, 2.5, leeches[1].Score)
+		assert.Equal(t, &wanikaniapi.LeechScore{
+			Correct: 5, CurrentStreak: 1, Incorrect: 5, IncorrectRate: 0.5, Score: 2.5,
+		}, leeches[1].Reading)
+		assert.InDelta(t, 0.2*2/8, leeches[1].Meaning.Score, 1e-9)
+		assert.Equal(t, stats[0], leeches[1].ReviewStatistic)
+		assert.Equal(t, subjects[0], leeches[1].Subject)
+
+		assert.InDelta(t, 1.0/11/27, leeches[2].Score, 1e-9)
+		assert.Equal(t, 0.0, leeches[2].Reading.Score)
+	})
+
+	t.Run("CriticalThreshold", func(t *testing.T) {
+		leeches, err := wanikaniapi.FindLeeches(&wanikaniapi.LeechParams{
+			CriticalThreshold: wanikaniapi.LeechCriticalThreshold,
+			ReviewStatistics:  stats,
+			Subjects:          subjects,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []wanikaniapi.WKID{2, 1}, leechSubjectIDs(leeches))
+	})
+
+	t.Run("MinScore", func(t *testing.T) {
+		leeches, err := wanikaniapi.FindLeeches(&wanikaniapi.LeechParams{
+			MinScore:         3,
+			ReviewStatistics: stats,
+			Subjects:         subjects,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []wanikaniapi.WKID{2}, leechSubjectIDs(leeches))
+	})
+
+	t.Run("StreakExponent", func(t *testing.T) {
+		leeches, err := wanikaniapi.FindLeeches(&wanikaniapi.LeechParams{
+			ReviewStatistics: stats,
+			StreakExponent:   1,
+			Subjects:         subjects,
+		})
+		assert.NoError(t, err)
+		assert.InDelta(t, 1.0/11/9, leeches[2].Score, 1e-9)
+	})
+
+	t.Run("MissingSubject", func(t *testing.T) {
+		_, err := wanikaniapi.FindLeeches(&wanikaniapi.LeechParams{
+			ReviewStatistics: stats,
+			Subjects:         subjects[1:],
+		})
+		assert.EqualError(t, err, "subject 1 not found")
+	})
+}
@@ -0,0 +1,208 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"sort"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewLessonQueue orders a user's available lessons the way WaniKani does for
+// the given LessonsPresentationOrder and splits them into batches.
+//
+// With LessonsPresentationOrderAscendingLevelThenSubject, lessons are ordered
+// by level, then by subject type (radicals, kanji, then vocabulary), then by
+// LessonPosition. With LessonsPresentationOrderAscendingLevelThenShuffled,
+// they're ordered by level and shuffled within each level, and with
+// LessonsPresentationOrderShuffled, they're shuffled entirely. Shuffles use
+// LessonQueueParams.Rand so that they can be made repeatable.
+func NewLessonQueue(params *LessonQueueParams) (*LessonQueue, error) {
+	order := params.Order
+	if order == "" {
+		order = LessonsPresentationOrderAscendingLevelThenSubject
+	}
+
+	batchSize := params.BatchSize
+	if batchSize == 0 {
+		batchSize = LessonQueueDefaultBatchSize
+	}
+	if batchSize < 0 {
+		return nil, fmt.Errorf("LessonQueueParams.BatchSize must be positive")
+	}
+
+	rnd := params.Rand
+	if rnd == nil {
+		rnd = globalRand{}
+	}
+
+	subjects := make(map[WKID]*Subject, len(params.Subjects))
+	for _, subject := range params.Subjects {
+		subjects[subject.ID] = subject
+	}
+
+	queue := &LessonQueue{}
+	for _, assignment := range params.Assignments {
+		data := assignment.Data
+		if data == nil || data.Hidden || data.UnlockedAt == nil || data.StartedAt != nil {
+			continue
+		}
+
+		subject, ok := subjects[data.SubjectID]
+		if !ok {
+			return nil, fmt.Errorf("subject %v not found", data.SubjectID)
+		}
+
+		queue.Items = append(queue.Items, &LessonQueueItem{Assignment: assignment, Subject: subject})
+	}
+
+	// Start from a stable order so that a shuffle with the same seed always
+	// gives the same result regardless of the order of Assignments.
+	sort.Slice(queue.Items, func(i, j int) bool {
+		return lessonQueueLess(queue.Items[i].Subject, queue.Items[j].Subject)
+	})
+
+	switch order {
+	case LessonsPresentationOrderAscendingLevelThenSubject:
+		// Already in order.
+
+	case LessonsPresentationOrderAscendingLevelThenShuffled:
+		for start := 0; start < len(queue.Items); {
+			end := start
+			for end < len(queue.Items) && queue.Items[end].Subject.Level() == queue.Items[start].Subject.Level() {
+				end++
+			}
+			items := queue.Items[start:end]
+			shuffle(rnd, len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+			start = end
+		}
+
+	case LessonsPresentationOrderShuffled:
+		shuffle(rnd, len(queue.Items), func(i, j int) {
+			queue.Items[i], queue.Items[j] = queue.Items[j], queue.Items[i]
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown lessons presentation order %q", order)
+	}
+
+	for start := 0; start < len(queue.Items); start += batchSize {
+		end := start + batchSize
+		if end > len(queue.Items) {
+			end = len(queue.Items)
+		}
+		queue.Batches = append(queue.Batches, queue.Items[start:end])
+	}
+
+	return queue, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LessonsPresentationOrder is the order in which WaniKani presents lessons,
+// which users set with the string UserPreferences.LessonsPresentationOrder.
+type LessonsPresentationOrder string
+
+// All possible values of LessonsPresentationOrder.
+const (
+	LessonsPresentationOrderAscendingLevelThenShuffled LessonsPresentationOrder = "ascending_level_then_shuffled"
+	LessonsPresentationOrderAscendingLevelThenSubject  LessonsPresentationOrder = "ascending_level_then_subject"
+	LessonsPresentationOrderShuffled                   LessonsPresentationOrder = "shuffled"
+)
+
+// LessonQueueDefaultBatchSize is the default LessonQueueParams.BatchSize,
+// which is also WaniKani's default.
+const LessonQueueDefaultBatchSize = 5
+
+// LessonQueue is a user's available lessons in order. See NewLessonQueue.
+type LessonQueue struct {
+	// Batches are Items split into batches. The last may be partial.
+	Batches [][]*LessonQueueItem
+
+	// Items are all of the available lessons in order.
+	Items []*LessonQueueItem
+}
+
+// LessonQueueItem is a lesson in a LessonQueue.
+type LessonQueueItem struct {
+	// Assignment is the lesson's assignment.
+	Assignment *Assignment
+
+	// Subject is the lesson's subject.
+	Subject *Subject
+}
+
+// LessonQueueParams are parameters for NewLessonQueue.
+type LessonQueueParams struct {
+	// Assignments are the user's assignments. Those that aren't available for
+	// lessons are skipped.
+	Assignments []*Assignment
+
+	// BatchSize is the number of lessons in each batch, usually the user's
+	// UserPreferences.LessonsBatchSize. Defaults to
+	// LessonQueueDefaultBatchSize.
+	BatchSize int
+
+	// Order is the order of lessons, usually the user's
+	// UserPreferences.LessonsPresentationOrder converted with
+	// LessonsPresentationOrder(...). Defaults to
+	// LessonsPresentationOrderAscendingLevelThenSubject.
+	Order LessonsPresentationOrder
+
+	// Rand is the source of randomness for shuffles. Defaults to the global
+	// source in math/rand. Use a seeded *rand.Rand for repeatable shuffles.
+	Rand Rand
+
+	// Subjects are the subjects of Assignments.
+	Subjects []*Subject
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// lessonQueueLess orders subjects by level, then by type, then by
+// LessonPosition, then by ID.
+func lessonQueueLess(a, b *Subject) bool {
+	if a.Level() != b.Level() {
+		return a.Level() < b.Level()
+	}
+
+	if aRank, bRank := subjectTypeRank(a), subjectTypeRank(b); aRank != bRank {
+		return aRank < bRank
+	}
+
+	var aPosition, bPosition int
+	if common := a.Common(); common != nil {
+		aPosition = common.LessonPosition
+	}
+	if common := b.Common(); common != nil {
+		bPosition = common.LessonPosition
+	}
+	if aPosition != bPosition {
+		return aPosition < bPosition
+	}
+
+	return a.ID < b.ID
+}
@@ -0,0 +1,136 @@
+package wanikaniapi_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewLessonQueue(t *testing.T) {
+	subject := func(id wanikaniapi.WKID, objectType wanikaniapi.WKObjectType, level, lessonPosition int) *wanikaniapi.Subject {
+		return fixtureSubject(id, objectType, wanikaniapi.SubjectCommonData{Level: level, LessonPosition: lessonPosition})
+	}
+
+	subjects := []*wanikaniapi.Subject{
+		subject(1, wanikaniapi.ObjectTypeRadical, 1, 2),
+		subject(2, wanikaniapi.ObjectTypeRadical, 1, 1),
+		subject(3, wanikaniapi.ObjectTypeKanji, 1, 0),
+		subject(4, wanikaniapi.ObjectTypeVocabulary, 1, 5),
+		subject(5, wanikaniapi.ObjectTypeKanaVocabulary, 1, 3),
+		subject(6, wanikaniapi.ObjectTypeRadical, 2, 0),
+		subject(7, wanikaniapi.ObjectTypeKanji, 2, 0),
+		subject(8, wanikaniapi.ObjectTypeKanji, 2, 1),
+	}
+
+	var assignments []*wanikaniapi.Assignment
+	for i := len(subjects) - 1; i >= 0; i-- {
+		assignments = append(assignments, &wanikaniapi.Assignment{Data: &wanikaniapi.AssignmentData{
+			SubjectID:  subjects[i].ID,
+			UnlockedAt: timePtr(subjects[i].Common().CreatedAt),
+		}})
+	}
+
+	// Not available for lessons: started, hidden, and locked.
+	assignments[0].Data.StartedAt = timePtr(subjects[0].Common().CreatedAt)
+	assignments = append(assignments,
+		&wanikaniapi.Assignment{Data: &wanikaniapi.AssignmentData{
+			Hidden: true, SubjectID: 9, UnlockedAt: timePtr(subjects[0].Common().CreatedAt),
+		}},
+		&wanikaniapi.Assignment{Data: &wanikaniapi.AssignmentData{SubjectID: 10}},
+	)
+
+	queueSubjectIDs := func(items []*wanikaniapi.LessonQueueItem) []wanikaniapi.WKID {
+		var ids []wanikaniapi.WKID
+		for _, item := range items {
+			ids = append(ids, item.Subject.ID)
+		}
+		return ids
+	}
+
+	t.Run("AscendingLevelThenSubject", func(t *testing.T) {
+		queue, err := wanikaniapi.NewLessonQueue(&wanikaniapi.LessonQueueParams{
+			Assignments: assignments,
+			BatchSize:   3,
+			Order:       wanikaniapi.LessonsPresentationOrderAscendingLevelThenSubject,
+			Subjects:    subjects,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []wanikaniapi.WKID{2, 1, 3, 5, 4, 6, 7}, queueSubjectIDs(queue.Items))
+		assert.Equal(t, 3, len(queue.Batches))
+		assert.Equal(t, []wanikaniapi.WKID{2, 1, 3}, queueSubjectIDs(queue.Batches[0]))
+		assert.Equal(t, []wanikaniapi.WKID{5, 4, 6}, queueSubjectIDs(queue.Batches[1]))
+		assert.Equal(t, []wanikaniapi.WKID{7}, queueSubjectIDs(queue.Batches[2]))
+		assert.Equal(t, assignments[6], queue.Items[0].Assignment)
+	})
+
+	t.Run("AscendingLevelThenShuffled", func(t *testing.T) {
+		queue, err := wanikaniapi.NewLessonQueue(&wanikaniapi.LessonQueueParams{
+			Assignments: assignments,
+			Order:       wanikaniapi.LessonsPresentationOrderAscendingLevelThenShuffled,
+			Rand:        rand.New(rand.NewSource(1)),
+			Subjects:    subjects,
+		})
+		assert.NoError(t, err)
+
+		ids := queueSubjectIDs(queue.Items)
+		assert.ElementsMatch(t, []wanikaniapi.WKID{1, 2, 3, 4, 5}, ids[:5])
+		assert.ElementsMatch(t, []wanikaniapi.WKID{6, 7}, ids[5:])
+
+		// Default batch size.
+		assert.Equal(t, 2, len(queue.Batches))
+		assert.Equal(t, wanikaniapi.LessonQueueDefaultBatchSize, len(queue.Batches[0]))
+	})
+
+	t.Run("Shuffled", func(t *testing.T) {
+		shuffle := func(seed int64, assignments []*wanikaniapi.Assignment) []wanikaniapi.WKID {
+			queue, err := wanikaniapi.NewLessonQueue(&wanikaniapi.LessonQueueParams{
+				Assignments: assignments,
+				Order:       wanikaniapi.LessonsPresentationOrderShuffled,
+				Rand:        rand.New(rand.NewSource(seed)),
+				Subjects:    subjects,
+			})
+			assert.NoError(t, err)
+			return queueSubjectIDs(queue.Items)
+		}
+
+		ids := shuffle(1, assignments)
+		assert.ElementsMatch(t, []wanikaniapi.WKID{1, 2, 3, 4, 5, 6, 7}, ids)
+
+		// The same seed gives the same order, even if assignments come in a
+		// different order.
+		sorted := make([]*wanikaniapi.Assignment, len(assignments))
+		copy(sorted, assignments)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Data.SubjectID < sorted[j].Data.SubjectID })
+		assert.Equal(t, ids, shuffle(1, sorted))
+
+		// Some seed shuffles differently.
+		var different bool
+		for seed := int64(2); seed < 10; seed++ {
+			if !reflect.DeepEqual(ids, shuffle(seed, assignments)) {
+				different = true
+				break
+			}
+		}
+		assert.True(t, different)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := wanikaniapi.NewLessonQueue(&wanikaniapi.LessonQueueParams{
+			Assignments: assignments,
+			Order:       "alphabetical",
+			Subjects:    subjects,
+		})
+		assert.EqualError(t, err, `unknown lessons presentation order "alphabetical"`)
+
+		_, err = wanikaniapi.NewLessonQueue(&wanikaniapi.LessonQueueParams{
+			Assignments: assignments,
+			Subjects:    subjects[1:],
+		})
+		assert.EqualError(t, err, "subject 1 not found")
+	})
+}
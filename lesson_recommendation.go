@@ -0,0 +1,405 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LessonRecommendationFetch fetches the user, their available lessons, their
+// assignments that are in progress, and the subjects and spaced repetition
+// systems that go with them, and recommends how many lessons to do today. See
+// RecommendLessons.
+func (c *Client) LessonRecommendationFetch(params *LessonRecommendationFetchParams) (*LessonRecommendation, error) {
+	user, err := c.UserGet(&UserGetParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []*Assignment
+	for _, listParams := range []*AssignmentListParams{
+		{ImmediatelyAvailableForLessons: Bool(true)},
+		{Burned: Bool(false), Started: Bool(true)},
+	} {
+		listParams := listParams
+		err = c.PageFully(func(id *WKID) (*PageObject, error) {
+			listParams.PageAfterID = id
+			page, err := c.AssignmentList(listParams)
+			if err != nil {
+				return nil, err
+			}
+
+			assignments = append(assignments, page.Data...)
+			return &page.PageObject, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Users only have assignments for subjects at or below their level.
+	levels := make([]int, user.Data.Level)
+	for i := range levels {
+		levels[i] = i + 1
+	}
+
+	var subjects []*Subject
+	err = c.PageFully(func(id *WKID) (*PageObject, error) {
+		page, err := c.SubjectList(&SubjectListParams{
+			ListParams: ListParams{PageAfterID: id},
+			Levels:     levels,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		subjects = append(subjects, page.Data...)
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var systems []*SpacedRepetitionSystem
+	err = c.PageFully(func(id *WKID) (*PageObject, error) {
+		page, err := c.SpacedRepetitionSystemList(&SpacedRepetitionSystemListParams{
+			ListParams: ListParams{PageAfterID: id},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		systems = append(systems, page.Data...)
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return RecommendLessons(&LessonRecommendationParams{
+		Accuracy:                params.Accuracy,
+		Assignments:             assignments,
+		Location:                params.Location,
+		MaxApprentice:           params.MaxApprentice,
+		MaxDailyReviews:         params.MaxDailyReviews,
+		Now:                     c.now(),
+		SpacedRepetitionSystems: systems,
+		Subjects:                subjects,
+		User:                    user,
+	})
+}
+
+// RecommendLessons recommends how many lessons to do today so that the user's
+// apprentice count and projected daily reviews stay under their targets, and
+// which lessons to do first.
+//
+// Lessons are projected as if they were all done now, and their reviews
+// projected with ForecastReviews over LessonRecommendationParams.ForecastDays.
+// Lessons that count toward leveling up come first: radicals of the user's
+// level that are components of its kanji, then the level's kanji. Other
+// lessons follow by level and type.
+func RecommendLessons(params *LessonRecommendationParams) (*LessonRecommendation, error) {
+	if params.Now.IsZero() {
+		return nil, fmt.Errorf("LessonRecommendationParams.Now must be set")
+	}
+	if params.User == nil || params.User.Data == nil {
+		return nil, fmt.Errorf("LessonRecommendationParams.User must be set")
+	}
+
+	forecastDays := params.ForecastDays
+	if forecastDays == 0 {
+		forecastDays = LessonRecommendationDefaultForecastDays
+	}
+
+	systems := newSubjectSystems(params.Subjects, params.SpacedRepetitionSystems)
+	level := params.User.Data.Level
+
+	subjects := make(map[WKID]*Subject, len(params.Subjects))
+	levelUpIDs := make(map[WKID]bool)
+	for _, subject := range params.Subjects {
+		subjects[subject.ID] = subject
+
+		if subject.KanjiData != nil && subject.KanjiData.Level == level {
+			levelUpIDs[subject.ID] = true
+			for _, id := range subject.KanjiData.ComponentSubjectIDs {
+				levelUpIDs[id] = true
+			}
+		}
+	}
+
+	recommendation := &LessonRecommendation{}
+	if params.User.Data.Preferences != nil {
+		recommendation.BatchSize = params.User.Data.Preferences.LessonsBatchSize
+	}
+
+	var inProgress []*Assignment
+	for _, assignment := range params.Assignments {
+		data := assignment.Data
+		if data == nil || data.Hidden || data.UnlockedAt == nil {
+			continue
+		}
+
+		subject, ok := subjects[data.SubjectID]
+		if !ok {
+			return nil, fmt.Errorf("subject %v not found", data.SubjectID)
+		}
+
+		if data.StartedAt == nil {
+			recommendation.Lessons = append(recommendation.Lessons, &LessonRecommendationItem{
+				Assignment: assignment,
+				LevelUp:    levelUpIDs[subject.ID] && subject.Level() == level,
+				Subject:    subject,
+			})
+			continue
+		}
+
+		srs, err := systems.forSubject(data.SubjectID)
+		if err != nil {
+			return nil, err
+		}
+
+		state, err := srs.Data.StageState(data.SRSStage)
+		if err != nil {
+			return nil, err
+		}
+		if state.Group == AssignmentStageGroupApprentice {
+			recommendation.NumApprentice++
+		}
+
+		inProgress = append(inProgress, assignment)
+	}
+
+	sort.Slice(recommendation.Lessons, func(i, j int) bool {
+		a, b := recommendation.Lessons[i], recommendation.Lessons[j]
+		if a.LevelUp != b.LevelUp {
+			return a.LevelUp
+		}
+		return subjectLess(a.Subject, b.Subject)
+	})
+
+	// forecastWith returns the most reviews projected for any day if the first
+	// numLessons lessons are done now.
+	forecastWith := func(numLessons int) (float64, error) {
+		assignments := make([]*Assignment, len(inProgress), len(inProgress)+numLessons)
+		copy(assignments, inProgress)
+
+		for _, item := range recommendation.Lessons[:numLessons] {
+			srs, err := systems.forSubject(item.Subject.ID)
+			if err != nil {
+				return 0, err
+			}
+
+			schedule, err := srs.Data.ScheduleLesson(params.Now)
+			if err != nil {
+				return 0, err
+			}
+
+			assignments = append(assignments, &Assignment{Data: &AssignmentData{
+				AvailableAt: schedule.AvailableAt,
+				SRSStage:    schedule.Stage,
+				SubjectID:   item.Subject.ID,
+			}})
+		}
+
+		forecast, err := ForecastReviews(&ReviewForecastParams{
+			Accuracy:                params.Accuracy,
+			Assignments:             assignments,
+			Days:                    forecastDays,
+			Location:                params.Location,
+			SpacedRepetitionSystems: params.SpacedRepetitionSystems,
+			Start:                   params.Now,
+			Subjects:                params.Subjects,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		var max float64
+		for _, day := range forecast.Daily {
+			if day.Count > max {
+				max = day.Count
+			}
+		}
+		return max, nil
+	}
+
+	numLessons := len(recommendation.Lessons)
+	recommendation.LimitedBy = LessonLimitAvailable
+
+	if params.MaxApprentice != 0 {
+		headroom := params.MaxApprentice - recommendation.NumApprentice
+		if headroom < 0 {
+			headroom = 0
+		}
+		if headroom < numLessons {
+			numLessons = headroom
+			recommendation.LimitedBy = LessonLimitApprentice
+		}
+	}
+
+	if params.MaxDailyReviews != 0 {
+		// Projected reviews only grow with more lessons, so search for the
+		// most lessons that stay under the target.
+		low, high := 0, numLessons
+		for low < high {
+			mid := (low + high + 1) / 2
+
+			max, err := forecastWith(mid)
+			if err != nil {
+				return nil, err
+			}
+
+			if max <= params.MaxDailyReviews {
+				low = mid
+			} else {
+				high = mid - 1
+			}
+		}
+
+		if low < numLessons {
+			numLessons = low
+			recommendation.LimitedBy = LessonLimitReviews
+		}
+	}
+
+	max, err := forecastWith(numLessons)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation.NumLessons = numLessons
+	recommendation.ProjectedMaxDailyReviews = max
+
+	if recommendation.BatchSize > 0 {
+		recommendation.NumBatches = (numLessons + recommendation.BatchSize - 1) / recommendation.BatchSize
+	}
+
+	return recommendation, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LessonRecommendationDefaultForecastDays is the default
+// LessonRecommendationParams.ForecastDays.
+const LessonRecommendationDefaultForecastDays = 7
+
+// LessonLimit is what limited the number of lessons in a
+// LessonRecommendation.
+type LessonLimit string
+
+// All possible values of LessonLimit.
+const (
+	LessonLimitApprentice LessonLimit = "apprentice"
+	LessonLimitAvailable  LessonLimit = "available"
+	LessonLimitReviews    LessonLimit = "reviews"
+)
+
+// LessonRecommendation is a recommendation of how many lessons to do today
+// and in which order. See RecommendLessons.
+type LessonRecommendation struct {
+	// BatchSize is the user's lesson batch size from their preferences.
+	BatchSize int
+
+	// LimitedBy is what limited NumLessons.
+	LimitedBy LessonLimit
+
+	// Lessons are all of the available lessons in the order that they should
+	// be done. The first NumLessons are recommended for today.
+	Lessons []*LessonRecommendationItem
+
+	// NumApprentice is the number of assignments currently in apprentice
+	// stages.
+	NumApprentice int
+
+	// NumBatches is the number of lesson batches needed for NumLessons at
+	// BatchSize. The last batch may be partial.
+	NumBatches int
+
+	// NumLessons is the recommended number of lessons to do today.
+	NumLessons int
+
+	// ProjectedMaxDailyReviews is the most reviews projected for any day of
+	// the forecast if NumLessons lessons are done now.
+	ProjectedMaxDailyReviews float64
+}
+
+// LessonRecommendationFetchParams are parameters for
+// LessonRecommendationFetch. See LessonRecommendationParams for their
+// meaning.
+type LessonRecommendationFetchParams struct {
+	Accuracy        map[int]float64
+	Location        *time.Location
+	MaxApprentice   int
+	MaxDailyReviews float64
+}
+
+// LessonRecommendationItem is an available lesson in a LessonRecommendation.
+type LessonRecommendationItem struct {
+	// Assignment is the lesson's assignment.
+	Assignment *Assignment
+
+	// LevelUp is whether the lesson counts toward leveling up, either as a
+	// kanji of the user's level or a component of one.
+	LevelUp bool
+
+	// Subject is the lesson's subject.
+	Subject *Subject
+}
+
+// LessonRecommendationParams are parameters for RecommendLessons.
+type LessonRecommendationParams struct {
+	// Accuracy is the expected accuracy of reviews by SRS stage for projecting
+	// reviews. See ReviewForecastParams.Accuracy.
+	Accuracy map[int]float64
+
+	// Assignments are the user's assignments, including available lessons and
+	// those in progress.
+	Assignments []*Assignment
+
+	// ForecastDays is the number of days to project reviews over. Defaults to
+	// LessonRecommendationDefaultForecastDays.
+	ForecastDays int
+
+	// Location is the user's time zone, which determines the boundaries of
+	// days. Defaults to UTC.
+	Location *time.Location
+
+	// MaxApprentice is the most assignments that should be in apprentice
+	// stages. Zero for no target.
+	MaxApprentice int
+
+	// MaxDailyReviews is the most reviews that should be projected for any
+	// day. Zero for no target.
+	MaxDailyReviews float64
+
+	// Now is the current time.
+	Now time.Time
+
+	// SpacedRepetitionSystems are the spaced repetition systems of Subjects.
+	SpacedRepetitionSystems []*SpacedRepetitionSystem
+
+	// Subjects are the subjects of Assignments, along with the kanji of the
+	// user's level so that their components can be found.
+	Subjects []*Subject
+
+	// User is the user, whose level and lesson batch size are used.
+	User *User
+}
@@ -0,0 +1,216 @@
+package wanikaniapi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	"github.com/brandur/wanikaniapi/wktesting"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestRecommendLessons(t *testing.T) {
+	srs := mustLoadSRS(t, "spaced_repetition_system.json")
+
+	now := time.Date(2021, 3, 14, 10, 20, 0, 0, time.UTC)
+	hour := func(day, hour int) time.Time {
+		return time.Date(2021, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	subjects := []*wanikaniapi.Subject{
+		// A component of the level's kanji.
+		fixtureRadical(1, srs.ID, 5),
+
+		// Not a component of any of the level's kanji.
+		fixtureRadical(2, srs.ID, 5),
+
+		fixtureKanji(10, srs.ID, 5, 1),
+		fixtureSubject(20, wanikaniapi.ObjectTypeVocabulary,
+			wanikaniapi.SubjectCommonData{Level: 5, SpacedRepetitionSystemID: srs.ID}),
+
+		// Left over from the previous level.
+		fixtureKanji(30, srs.ID, 4),
+
+		fixtureKanji(40, srs.ID, 4),
+		fixtureKanji(41, srs.ID, 4),
+		fixtureKanji(42, srs.ID, 4),
+		fixtureKanji(43, srs.ID, 4),
+	}
+
+	lesson := func(subjectID wanikaniapi.WKID) *wanikaniapi.Assignment {
+		return &wanikaniapi.Assignment{Data: &wanikaniapi.AssignmentData{
+			SubjectID: subjectID, UnlockedAt: timePtr(hour(1, 0)),
+		}}
+	}
+	started := func(subjectID wanikaniapi.WKID, stage int, availableAt time.Time) *wanikaniapi.Assignment {
+		return fixtureAssignment(subjectID, stage, timePtr(hour(1, 0)), &availableAt, nil)
+	}
+
+	params := &wanikaniapi.LessonRecommendationParams{
+		Assignments: []*wanikaniapi.Assignment{
+			lesson(20),
+			lesson(30),
+			lesson(2),
+			lesson(10),
+			lesson(1),
+
+			// Apprentice, and each has reviews today, tomorrow, and on the
+			// 17th.
+			started(40, 2, hour(14, 9)),
+			started(41, 2, hour(14, 9)),
+			started(42, 2, hour(14, 9)),
+
+			// Guru.
+			started(43, 5, hour(16, 10)),
+		},
+		Now:                     now,
+		SpacedRepetitionSystems: []*wanikaniapi.SpacedRepetitionSystem{srs},
+		Subjects:                subjects,
+		User: &wanikaniapi.User{Data: &wanikaniapi.UserData{
+			Level:       5,
+			Preferences: &wanikaniapi.UserPreferences{LessonsBatchSize: 3},
+		}},
+	}
+
+	lessonSubjectIDs := func(recommendation *wanikaniapi.LessonRecommendation) []wanikaniapi.WKID {
+		var ids []wanikaniapi.WKID
+		for _, item := range recommendation.Lessons {
+			ids = append(ids, item.Subject.ID)
+		}
+		return ids
+	}
+
+	t.Run("NoTargets", func(t *testing.T) {
+		recommendation, err := wanikaniapi.RecommendLessons(params)
+		assert.NoError(t, err)
+
+		// Level-up lessons first, then the rest by level and type.
+		assert.Equal(t, []wanikaniapi.WKID{1, 10, 30, 2, 20}, lessonSubjectIDs(recommendation))
+		assert.True(t, recommendation.Lessons[0].LevelUp)
+		assert.True(t, recommendation.Lessons[1].LevelUp)
+		assert.False(t, recommendation.Lessons[2].LevelUp)
+
+		assert.Equal(t, 3, recommendation.NumApprentice)
+		assert.Equal(t, 5, recommendation.NumLessons)
+		assert.Equal(t, wanikaniapi.LessonLimitAvailable, recommendation.LimitedBy)
+		assert.Equal(t, 3, recommendation.BatchSize)
+		assert.Equal(t, 2, recommendation.NumBatches)
+
+		// Each lesson has two reviews today on top of the three already due.
+		assert.Equal(t, 13.0, recommendation.ProjectedMaxDailyReviews)
+	})
+
+	t.Run("MaxApprentice", func(t *testing.T) {
+		p := *params
+		p.MaxApprentice = 5
+
+		recommendation, err := wanikaniapi.RecommendLessons(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, recommendation.NumLessons)
+		assert.Equal(t, wanikaniapi.LessonLimitApprentice, recommendation.LimitedBy)
+		assert.Equal(t, 1, recommendation.NumBatches)
+
+		p.MaxApprentice = 2
+		recommendation, err = wanikaniapi.RecommendLessons(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, recommendation.NumLessons)
+		assert.Equal(t, 0, recommendation.NumBatches)
+	})
+
+	t.Run("MaxDailyReviews", func(t *testing.T) {
+		p := *params
+		p.MaxDailyReviews = 8
+
+		recommendation, err := wanikaniapi.RecommendLessons(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, recommendation.NumLessons)
+		assert.Equal(t, wanikaniapi.LessonLimitReviews, recommendation.LimitedBy)
+		assert.Equal(t, 7.0, recommendation.ProjectedMaxDailyReviews)
+
+		// Already over the target without any lessons.
+		p.MaxDailyReviews = 2
+		recommendation, err = wanikaniapi.RecommendLessons(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, recommendation.NumLessons)
+		assert.Equal(t, 3.0, recommendation.ProjectedMaxDailyReviews)
+
+		// The tighter of the two targets wins.
+		p.MaxApprentice = 4
+		p.MaxDailyReviews = 8
+		recommendation, err = wanikaniapi.RecommendLessons(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, recommendation.NumLessons)
+		assert.Equal(t, wanikaniapi.LessonLimitApprentice, recommendation.LimitedBy)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		p := *params
+		p.Subjects = subjects[1:]
+		_, err := wanikaniapi.RecommendLessons(&p)
+		assert.EqualError(t, err, "subject 1 not found")
+
+		p = *params
+		p.User = nil
+		_, err = wanikaniapi.RecommendLessons(&p)
+		assert.EqualError(t, err, "LessonRecommendationParams.User must be set")
+	})
+}
+
+func TestLessonRecommendationFetch(t *testing.T) {
+	client := wktesting.LocalClient()
+	client.Clock = fixedClock{time.Date(2021, 3, 14, 10, 20, 0, 0, time.UTC)}
+
+	user, err := ioutil.ReadFile("testdata/user.json")
+	assert.NoError(t, err)
+
+	srs, err := ioutil.ReadFile("testdata/spaced_repetition_system.json")
+	assert.NoError(t, err)
+
+	collection := func(data string) *wanikaniapi.RecordedResponse {
+		return &wanikaniapi.RecordedResponse{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {"per_page": 500, "next_url": null, "previous_url": null},
+			"data": [` + data + `]
+		}`)}
+	}
+
+	client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+		{StatusCode: http.StatusOK, Body: user},
+		collection(`{"id": 1, "object": "assignment", "data": {
+			"subject_id": 440, "unlocked_at": "2021-03-01T00:00:00.000000Z"
+		}}`),
+		collection(`{"id": 2, "object": "assignment", "data": {
+			"subject_id": 441, "srs_stage": 1,
+			"available_at": "2021-03-14T12:00:00.000000Z",
+			"unlocked_at": "2021-03-01T00:00:00.000000Z",
+			"started_at": "2021-03-01T00:00:00.000000Z"
+		}}`),
+		collection(`
+			{"id": 440, "object": "kanji", "data": {"level": 5, "spaced_repetition_system_id": 1}},
+			{"id": 441, "object": "kanji", "data": {"level": 5, "spaced_repetition_system_id": 1}}
+		`),
+		collection(string(srs)),
+	}
+
+	recommendation, err := client.LessonRecommendationFetch(&wanikaniapi.LessonRecommendationFetchParams{
+		MaxApprentice: 10,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, recommendation.NumApprentice)
+	assert.Equal(t, 1, recommendation.NumLessons)
+	assert.Equal(t, 10, recommendation.BatchSize)
+	assert.True(t, recommendation.Lessons[0].LevelUp)
+
+	assert.Equal(t, 5, len(client.RecordedRequests))
+	assert.Equal(t, "/v2/user", client.RecordedRequests[0].Path)
+	assert.Equal(t, "/v2/assignments", client.RecordedRequests[1].Path)
+	assert.Equal(t, "immediately_available_for_lessons=true", client.RecordedRequests[1].Query)
+	assert.Equal(t, "/v2/assignments", client.RecordedRequests[2].Path)
+	assert.Equal(t, "burned=false&started=true", client.RecordedRequests[2].Query)
+	assert.Equal(t, "/v2/subjects", client.RecordedRequests[3].Path)
+	assert.Equal(t, "levels=1,2,3,4,5", wktesting.MustQueryUnescape(client.RecordedRequests[3].Query))
+	assert.Equal(t, "/v2/spaced_repetition_systems", client.RecordedRequests[4].Path)
+}
@@ -0,0 +1,344 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LevelPaceFetch fetches all of the user's level progressions and resets and
+// analyzes how quickly they've been leveling up. See NewLevelPace.
+func (c *Client) LevelPaceFetch() (*LevelPace, error) {
+	var progressions []*LevelProgression
+	err := c.PageFully(func(id *WKID) (*PageObject, error) {
+		page, err := c.LevelProgressionList(&LevelProgressionListParams{
+			ListParams: ListParams{PageAfterID: id},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		progressions = append(progressions, page.Data...)
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resets []*Reset
+	err = c.PageFully(func(id *WKID) (*PageObject, error) {
+		page, err := c.ResetList(&ResetListParams{
+			ListParams: ListParams{PageAfterID: id},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resets = append(resets, page.Data...)
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLevelPace(&LevelPaceParams{
+		LevelProgressions: progressions,
+		Now:               c.now(),
+		Resets:            resets,
+	})
+}
+
+// NewLevelPace analyzes a user's level progressions to find how long each
+// level took, their typical pace, and the resets that abandoned levels.
+//
+// A level's duration runs from when it was unlocked until it was passed.
+// Levels abandoned by a reset and levels that are still in progress are
+// excluded from the pace statistics.
+func NewLevelPace(params *LevelPaceParams) (*LevelPace, error) {
+	recentLevels := params.RecentLevels
+	if recentLevels == 0 {
+		recentLevels = LevelPaceDefaultRecentLevels
+	}
+	if recentLevels < 0 {
+		return nil, fmt.Errorf("LevelPaceParams.RecentLevels must be positive")
+	}
+
+	pace := &LevelPace{}
+
+	for _, reset := range params.Resets {
+		// Unconfirmed resets haven't been applied to the user's account.
+		if reset.Data == nil || reset.Data.ConfirmedAt == nil {
+			continue
+		}
+		pace.Resets = append(pace.Resets, &LevelPaceReset{Reset: reset})
+	}
+	sort.SliceStable(pace.Resets, func(i, j int) bool {
+		return pace.Resets[i].Reset.Data.ConfirmedAt.Before(*pace.Resets[j].Reset.Data.ConfirmedAt)
+	})
+
+	for _, progression := range params.LevelProgressions {
+		data := progression.Data
+		if data == nil {
+			continue
+		}
+
+		level := &LevelPaceLevel{
+			Abandoned:   data.AbandonedAt != nil,
+			Level:       data.Level,
+			PassedAt:    data.PassedAt,
+			Progression: progression,
+			UnlockedAt:  levelProgressionUnlockedAt(data),
+		}
+
+		switch {
+		case data.PassedAt != nil:
+			level.Duration = data.PassedAt.Sub(level.UnlockedAt)
+		case !level.Abandoned:
+			level.Duration = params.Now.Sub(level.UnlockedAt)
+			level.InProgress = true
+		}
+
+		if level.Abandoned {
+			if reset := findLevelPaceReset(pace.Resets, level); reset != nil {
+				level.Reset = reset.Reset
+				reset.AbandonedLevels = append(reset.AbandonedLevels, level)
+			}
+		}
+
+		pace.Levels = append(pace.Levels, level)
+	}
+	sort.SliceStable(pace.Levels, func(i, j int) bool {
+		return pace.Levels[i].UnlockedAt.Before(pace.Levels[j].UnlockedAt)
+	})
+
+	for _, level := range pace.Levels {
+		if level.InProgress {
+			pace.CurrentLevel = level
+		}
+		if level.Abandoned || level.PassedAt == nil {
+			continue
+		}
+		pace.durations = append(pace.durations, level.Duration)
+		pace.NumPassed++
+	}
+
+	pace.Median = pace.Percentile(50)
+
+	recent := pace.durations
+	if len(recent) > recentLevels {
+		recent = recent[len(recent)-recentLevels:]
+	}
+	pace.RecentMedian = percentile(recent, 50)
+
+	return pace, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LevelPaceDefaultRecentLevels is the default number of recently passed
+// levels used to calculate LevelPace.RecentMedian.
+const LevelPaceDefaultRecentLevels = 5
+
+// LevelPace is an analysis of how quickly a user has been leveling up. See
+// NewLevelPace.
+type LevelPace struct {
+	// CurrentLevel is the level that's in progress, if any.
+	CurrentLevel *LevelPaceLevel
+
+	// Levels are all of the user's levels ordered by when they were unlocked,
+	// including those that were abandoned and the one that's in progress.
+	Levels []*LevelPaceLevel
+
+	// Median is the median duration of passed levels, excluding abandoned
+	// ones. Zero if no levels have been passed.
+	Median time.Duration
+
+	// NumPassed is the number of passed levels that count toward pace.
+	NumPassed int
+
+	// RecentMedian is the median duration of the most recently passed levels,
+	// which is used to project future level-ups. Zero if no levels have been
+	// passed.
+	RecentMedian time.Duration
+
+	// Resets are the user's confirmed resets ordered by when they were
+	// confirmed, along with the levels that they abandoned.
+	Resets []*LevelPaceReset
+
+	// durations are the durations of passed levels that count toward pace in
+	// the order the levels were unlocked.
+	durations []time.Duration
+}
+
+// Percentile returns the given percentile, from 0 to 100, of the durations of
+// passed levels, interpolating between levels where necessary. Abandoned
+// levels are excluded. Returns zero if no levels have been passed.
+func (p *LevelPace) Percentile(pct float64) time.Duration {
+	return percentile(p.durations, pct)
+}
+
+// ProjectLevel estimates when the user will reach the given level if they
+// keep leveling up at RecentMedian.
+//
+// The current level is assumed to take RecentMedian from when it was
+// unlocked, or to be passed now if it's already taken longer, and each level
+// after it to take RecentMedian. For the current level or one before it, the
+// time that the level was actually unlocked is returned, skipping levels that
+// were abandoned by a reset. Returns an error if there's no level in progress,
+// no recent pace to project from, or no record of a level before the current
+// one.
+func (p *LevelPace) ProjectLevel(level int, now time.Time) (time.Time, error) {
+	if p.CurrentLevel == nil {
+		return time.Time{}, fmt.Errorf("no level in progress to project from")
+	}
+
+	if level <= p.CurrentLevel.Level {
+		// Levels are ordered by when they were unlocked, so search backwards
+		// for the most recent time the level was reached.
+		for i := len(p.Levels) - 1; i >= 0; i-- {
+			if p.Levels[i].Level == level && !p.Levels[i].Abandoned {
+				return p.Levels[i].UnlockedAt, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("no level progression for level %v", level)
+	}
+
+	if p.RecentMedian == 0 {
+		return time.Time{}, fmt.Errorf("no passed levels to project from")
+	}
+
+	passAt := p.CurrentLevel.UnlockedAt.Add(p.RecentMedian)
+	if passAt.Before(now) {
+		passAt = now
+	}
+
+	return passAt.Add(time.Duration(level-p.CurrentLevel.Level-1) * p.RecentMedian), nil
+}
+
+// LevelPaceLevel is a single level in a LevelPace.
+type LevelPaceLevel struct {
+	// Abandoned is whether the level was abandoned, usually by a reset.
+	Abandoned bool
+
+	// Duration is how long the level took from being unlocked to being
+	// passed. For the level in progress, it's how long it's taken so far.
+	// Zero for abandoned levels that were never passed.
+	Duration time.Duration
+
+	// InProgress is whether the level is the user's current level.
+	InProgress bool
+
+	// Level is the level's number.
+	Level int
+
+	// PassedAt is when the level was passed. Nil if it hasn't been.
+	PassedAt *time.Time
+
+	// Progression is the level progression that the level was built from.
+	Progression *LevelProgression
+
+	// Reset is the reset that abandoned the level, if any.
+	Reset *Reset
+
+	// UnlockedAt is when the level was unlocked, falling back to when its
+	// level progression was created.
+	UnlockedAt time.Time
+}
+
+// LevelPaceParams are parameters for NewLevelPace.
+type LevelPaceParams struct {
+	// LevelProgressions are the user's level progressions.
+	LevelProgressions []*LevelProgression
+
+	// Now is the current time, which is used for the duration of the level in
+	// progress.
+	Now time.Time
+
+	// RecentLevels is the number of recently passed levels used to calculate
+	// LevelPace.RecentMedian. Defaults to LevelPaceDefaultRecentLevels.
+	RecentLevels int
+
+	// Resets are the user's resets.
+	Resets []*Reset
+}
+
+// LevelPaceReset is a confirmed reset in a LevelPace.
+type LevelPaceReset struct {
+	// AbandonedLevels are the levels that the reset abandoned.
+	AbandonedLevels []*LevelPaceLevel
+
+	// Reset is the reset.
+	Reset *Reset
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// findLevelPaceReset finds the reset that abandoned a level: the earliest
+// reset confirmed after the level was unlocked whose range of levels includes
+// it.
+func findLevelPaceReset(resets []*LevelPaceReset, level *LevelPaceLevel) *LevelPaceReset {
+	for _, reset := range resets {
+		data := reset.Reset.Data
+		if data.ConfirmedAt.Before(level.UnlockedAt) {
+			continue
+		}
+		if level.Level < data.TargetLevel || level.Level > data.OriginalLevel {
+			continue
+		}
+		return reset
+	}
+	return nil
+}
+
+func levelProgressionUnlockedAt(data *LevelProgressionData) time.Time {
+	if data.UnlockedAt != nil {
+		return *data.UnlockedAt
+	}
+	return data.CreatedAt
+}
+
+// percentile returns the given percentile of durations by linear
+// interpolation between the closest ranks.
+func percentile(durations []time.Duration, pct float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := math.Max(0, math.Min(100, pct)) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	fraction := rank - float64(lower)
+	return sorted[lower] + time.Duration(fraction*float64(sorted[upper]-sorted[lower]))
+}
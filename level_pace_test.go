@@ -0,0 +1,176 @@
+package wanikaniapi_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	"github.com/brandur/wanikaniapi/wktesting"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewLevelPace(t *testing.T) {
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2021, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	days := func(n float64) time.Duration {
+		return time.Duration(n * float64(24*time.Hour))
+	}
+
+	reset := &wanikaniapi.Reset{
+		Object: wanikaniapi.Object{ID: 1},
+		Data: &wanikaniapi.ResetData{
+			ConfirmedAt:   timePtr(day(2, 1)),
+			OriginalLevel: 4,
+			TargetLevel:   3,
+		},
+	}
+
+	// Never confirmed, so it didn't abandon anything.
+	unconfirmedReset := &wanikaniapi.Reset{
+		Object: wanikaniapi.Object{ID: 2},
+		Data:   &wanikaniapi.ResetData{OriginalLevel: 5, TargetLevel: 1},
+	}
+
+	progressions := []*wanikaniapi.LevelProgression{
+		fixtureLevelProgression(1, day(1, 1), timePtr(day(1, 8)), nil),
+		fixtureLevelProgression(2, day(1, 8), timePtr(day(1, 14)), nil),
+
+		// Passed and not, but both abandoned by the reset.
+		fixtureLevelProgression(3, day(1, 14), timePtr(day(1, 24)), timePtr(day(2, 1))),
+		fixtureLevelProgression(4, day(1, 24), nil, timePtr(day(2, 1))),
+
+		fixtureLevelProgression(3, day(2, 1), timePtr(day(2, 9)), nil),
+		fixtureLevelProgression(4, day(2, 9), timePtr(day(2, 18)), nil),
+		fixtureLevelProgression(5, day(2, 18), nil, nil),
+	}
+
+	pace, err := wanikaniapi.NewLevelPace(&wanikaniapi.LevelPaceParams{
+		LevelProgressions: progressions,
+		Now:               day(2, 20),
+		RecentLevels:      2,
+		Resets:            []*wanikaniapi.Reset{unconfirmedReset, reset},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 7, len(pace.Levels))
+	assert.Equal(t, 4, pace.NumPassed)
+
+	var durations []time.Duration
+	var abandoned []bool
+	for _, level := range pace.Levels {
+		durations = append(durations, level.Duration)
+		abandoned = append(abandoned, level.Abandoned)
+	}
+	assert.Equal(t, []time.Duration{days(7), days(6), days(10), 0, days(8), days(9), days(2)}, durations)
+	assert.Equal(t, []bool{false, false, true, true, false, false, false}, abandoned)
+
+	assert.Equal(t, 5, pace.CurrentLevel.Level)
+	assert.True(t, pace.CurrentLevel.InProgress)
+
+	assert.Equal(t, 1, len(pace.Resets))
+	assert.Equal(t, reset, pace.Resets[0].Reset)
+	assert.Equal(t, []*wanikaniapi.LevelPaceLevel{pace.Levels[2], pace.Levels[3]}, pace.Resets[0].AbandonedLevels)
+	assert.Equal(t, reset, pace.Levels[2].Reset)
+
+	// Passed levels took 6, 7, 8, and 9 days.
+	assert.Equal(t, days(7.5), pace.Median)
+	assert.Equal(t, days(6), pace.Percentile(0))
+	assert.Equal(t, days(6.75), pace.Percentile(25))
+	assert.Equal(t, days(9), pace.Percentile(100))
+
+	// The two most recent levels took 8 and 9 days.
+	assert.Equal(t, days(8.5), pace.RecentMedian)
+
+	t.Run("ProjectLevel", func(t *testing.T) {
+		at, err := pace.ProjectLevel(5, day(2, 20))
+		assert.NoError(t, err)
+		assert.Equal(t, day(2, 18), at)
+
+		// The current level is passed 8.5 days after it was unlocked, and
+		// level 7 is 8.5 days after that.
+		at, err = pace.ProjectLevel(7, day(2, 20))
+		assert.NoError(t, err)
+		assert.Equal(t, day(3, 7), at)
+
+		// The current level is already taking longer than usual, so it's
+		// assumed to be passed now.
+		at, err = pace.ProjectLevel(7, day(3, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, day(3, 1).Add(days(8.5)), at)
+
+		// Past levels are when they were actually unlocked, skipping the
+		// abandoned attempt at level 3.
+		at, err = pace.ProjectLevel(3, day(2, 20))
+		assert.NoError(t, err)
+		assert.Equal(t, day(2, 1), at)
+
+		at, err = pace.ProjectLevel(1, day(2, 20))
+		assert.NoError(t, err)
+		assert.Equal(t, day(1, 1), at)
+
+		_, err = pace.ProjectLevel(0, day(2, 20))
+		assert.EqualError(t, err, "no level progression for level 0")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		pace, err := wanikaniapi.NewLevelPace(&wanikaniapi.LevelPaceParams{Now: day(2, 20)})
+		assert.NoError(t, err)
+		assert.Nil(t, pace.CurrentLevel)
+		assert.Equal(t, time.Duration(0), pace.Median)
+		assert.Equal(t, time.Duration(0), pace.Percentile(90))
+
+		_, err = pace.ProjectLevel(10, day(2, 20))
+		assert.EqualError(t, err, "no level in progress to project from")
+
+		pace, err = wanikaniapi.NewLevelPace(&wanikaniapi.LevelPaceParams{
+			LevelProgressions: progressions[6:],
+			Now:               day(2, 20),
+		})
+		assert.NoError(t, err)
+		_, err = pace.ProjectLevel(10, day(2, 20))
+		assert.EqualError(t, err, "no passed levels to project from")
+	})
+}
+
+func TestLevelPaceFetch(t *testing.T) {
+	client := wktesting.LocalClient()
+	client.Clock = fixedClock{time.Date(2021, 2, 20, 0, 0, 0, 0, time.UTC)}
+
+	client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {"per_page": 500, "next_url": null, "previous_url": null},
+			"total_count": 2,
+			"data": [
+				{"id": 1, "object": "level_progression", "data": {
+					"level": 1,
+					"unlocked_at": "2021-02-01T00:00:00.000000Z",
+					"passed_at": "2021-02-08T00:00:00.000000Z"
+				}},
+				{"id": 2, "object": "level_progression", "data": {
+					"level": 2,
+					"unlocked_at": "2021-02-08T00:00:00.000000Z"
+				}}
+			]
+		}`)},
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {"per_page": 500, "next_url": null, "previous_url": null},
+			"total_count": 0,
+			"data": []
+		}`)},
+	}
+
+	pace, err := client.LevelPaceFetch()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, len(pace.Levels))
+	assert.Equal(t, 7*24*time.Hour, pace.Median)
+	assert.Equal(t, 12*24*time.Hour, pace.CurrentLevel.Duration)
+
+	assert.Equal(t, 2, len(client.RecordedRequests))
+	assert.Equal(t, "/v2/level_progressions", client.RecordedRequests[0].Path)
+	assert.Equal(t, "/v2/resets", client.RecordedRequests[1].Path)
+}
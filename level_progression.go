@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -93,3 +94,13 @@ type LevelProgressionPage struct {
 	PageObject
 	Data []*LevelProgression `json:"data"`
 }
+
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p LevelProgressionPage) MarshalJSON() ([]byte, error) {
+	type page LevelProgressionPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
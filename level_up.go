@@ -0,0 +1,359 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LevelUpStatusFetch fetches the user, along with the subjects and
+// assignments of their current level and all spaced repetition systems, and
+// returns the user's progress toward leveling up. See NewLevelUpStatus.
+func (c *Client) LevelUpStatusFetch() (*LevelUpStatus, error) {
+	user, err := c.UserGet(&UserGetParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	level := user.Data.Level
+
+	var subjects []*Subject
+	err = c.PageFully(func(id *WKID) (*PageObject, error) {
+		page, err := c.SubjectList(&SubjectListParams{
+			ListParams: ListParams{PageAfterID: id},
+			Levels:     []int{level},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		subjects = append(subjects, page.Data...)
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []*Assignment
+	err = c.PageFully(func(id *WKID) (*PageObject, error) {
+		page, err := c.AssignmentList(&AssignmentListParams{
+			ListParams: ListParams{PageAfterID: id},
+			Levels:     []int{level},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		assignments = append(assignments, page.Data...)
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var systems []*SpacedRepetitionSystem
+	err = c.PageFully(func(id *WKID) (*PageObject, error) {
+		page, err := c.SpacedRepetitionSystemList(&SpacedRepetitionSystemListParams{
+			ListParams: ListParams{PageAfterID: id},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		systems = append(systems, page.Data...)
+		return &page.PageObject, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLevelUpStatus(&LevelUpStatusParams{
+		Assignments:             assignments,
+		Level:                   level,
+		Now:                     c.now(),
+		SpacedRepetitionSystems: systems,
+		Subjects:                subjects,
+	})
+}
+
+// NewLevelUpStatus returns a user's progress toward leveling up from the given
+// level, which requires passing LevelUpKanjiFraction of the level's kanji.
+//
+// The earliest level-up time assumes that every review is answered correctly
+// as soon as it becomes available, that lessons are done as soon as they're
+// unlocked, and that a kanji unlocks once all of its components have passed.
+func NewLevelUpStatus(params *LevelUpStatusParams) (*LevelUpStatus, error) {
+	calc := &levelUpCalculator{
+		assignments: make(map[WKID]*Assignment, len(params.Assignments)),
+		now:         params.Now,
+		passAt:      make(map[WKID]time.Time),
+		subjects:    make(map[WKID]*Subject, len(params.Subjects)),
+		systems:     newSubjectSystems(params.Subjects, params.SpacedRepetitionSystems),
+	}
+
+	for _, assignment := range params.Assignments {
+		if assignment.Data != nil {
+			calc.assignments[assignment.Data.SubjectID] = assignment
+		}
+	}
+
+	for _, subject := range params.Subjects {
+		calc.subjects[subject.ID] = subject
+	}
+
+	status := &LevelUpStatus{Level: params.Level}
+
+	var unpassedPassAt []time.Time
+	for _, subject := range params.Subjects {
+		if subject.KanjiData == nil || subject.KanjiData.Level != params.Level || subject.KanjiData.HiddenAt != nil {
+			continue
+		}
+
+		status.NumKanji++
+
+		if calc.passed(subject.ID) {
+			status.NumKanjiPassed++
+			continue
+		}
+
+		if !calc.unlocked(subject.ID) {
+			blocked := &LevelUpBlockedKanji{Kanji: subject}
+			for _, id := range subject.KanjiData.ComponentSubjectIDs {
+				// Like in earliestPassAt, components that aren't in Subjects
+				// are from other levels and assumed to be passed.
+				component, ok := calc.subjects[id]
+				if ok && !calc.passed(id) {
+					blocked.BlockingSubjectIDs = append(blocked.BlockingSubjectIDs, id)
+					blocked.BlockingSubjects = append(blocked.BlockingSubjects, component)
+				}
+			}
+			if len(blocked.BlockingSubjectIDs) > 0 {
+				status.BlockedKanji = append(status.BlockedKanji, blocked)
+			}
+		}
+
+		passAt, err := calc.earliestPassAt(subject.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+		unpassedPassAt = append(unpassedPassAt, passAt)
+	}
+
+	status.NumKanjiRequired = int(math.Ceil(float64(status.NumKanji) * LevelUpKanjiFraction))
+	if status.NumKanjiPassed < status.NumKanjiRequired {
+		status.NumKanjiNeeded = status.NumKanjiRequired - status.NumKanjiPassed
+	}
+
+	if status.NumKanji > 0 {
+		if status.NumKanjiNeeded == 0 {
+			status.EarliestLevelUpAt = &params.Now
+		} else {
+			sort.Slice(unpassedPassAt, func(i, j int) bool { return unpassedPassAt[i].Before(unpassedPassAt[j]) })
+			status.EarliestLevelUpAt = &unpassedPassAt[status.NumKanjiNeeded-1]
+		}
+	}
+
+	return status, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// LevelUpKanjiFraction is the fraction of a level's kanji that must be passed
+// to level up.
+const LevelUpKanjiFraction = 0.9
+
+// LevelUpBlockedKanji is a kanji that's still locked because some of its
+// components haven't been passed.
+type LevelUpBlockedKanji struct {
+	// BlockingSubjectIDs are the IDs of the kanji's components that haven't
+	// been passed, usually radicals. Only components in
+	// LevelUpStatusParams.Subjects are included. Others are from earlier
+	// levels and assumed to be passed.
+	BlockingSubjectIDs []WKID
+
+	// BlockingSubjects are the subjects of BlockingSubjectIDs in the same
+	// order.
+	BlockingSubjects []*Subject
+
+	// Kanji is the locked kanji.
+	Kanji *Subject
+}
+
+// LevelUpStatus is a user's progress toward leveling up.
+type LevelUpStatus struct {
+	// BlockedKanji are the level's kanji that are locked, along with the
+	// components that are blocking them.
+	BlockedKanji []*LevelUpBlockedKanji
+
+	// EarliestLevelUpAt is the earliest time that the user could level up. If
+	// they've already passed enough kanji, it's the current time. Nil if the
+	// level has no kanji.
+	EarliestLevelUpAt *time.Time
+
+	// Level is the level being tracked.
+	Level int
+
+	// NumKanji is the number of kanji in the level.
+	NumKanji int
+
+	// NumKanjiNeeded is the number of kanji that still need to be passed to
+	// level up.
+	NumKanjiNeeded int
+
+	// NumKanjiPassed is the number of the level's kanji that have been passed.
+	NumKanjiPassed int
+
+	// NumKanjiRequired is the number of the level's kanji that must be passed
+	// to level up.
+	NumKanjiRequired int
+}
+
+// LevelUpStatusParams are parameters for NewLevelUpStatus.
+type LevelUpStatusParams struct {
+	// Assignments are the user's assignments for the level's subjects.
+	Assignments []*Assignment
+
+	// Level is the level to track, usually the user's current level.
+	Level int
+
+	// Now is the current time.
+	Now time.Time
+
+	// SpacedRepetitionSystems are the spaced repetition systems of Subjects.
+	SpacedRepetitionSystems []*SpacedRepetitionSystem
+
+	// Subjects are the level's subjects. Components of the level's kanji that
+	// aren't included are assumed to be passed.
+	Subjects []*Subject
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+type levelUpCalculator struct {
+	assignments map[WKID]*Assignment
+	now         time.Time
+	passAt      map[WKID]time.Time
+	subjects    map[WKID]*Subject
+	systems     *subjectSystems
+}
+
+// earliestPassAt returns the earliest time that a subject could pass. visiting
+// holds subjects whose pass time is being calculated to guard against cycles.
+func (c *levelUpCalculator) earliestPassAt(id WKID, visiting map[WKID]bool) (time.Time, error) {
+	if passAt, ok := c.passAt[id]; ok {
+		return passAt, nil
+	}
+
+	if c.passed(id) {
+		return c.now, nil
+	}
+
+	subject, ok := c.subjects[id]
+	if !ok {
+		// A component from another level that we don't know about. Assume
+		// that it's passed.
+		return c.now, nil
+	}
+
+	if visiting == nil {
+		visiting = make(map[WKID]bool)
+	}
+	if visiting[id] {
+		return time.Time{}, fmt.Errorf("component cycle involving subject %v", id)
+	}
+	visiting[id] = true
+	defer delete(visiting, id)
+
+	srs, err := c.systems.forSubject(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var stage int
+	var at time.Time
+
+	assignment := c.assignments[id]
+	switch {
+	case c.unlocked(id) && assignment.Data.StartedAt != nil:
+		stage = assignment.Data.SRSStage
+		at = c.now
+		if assignment.Data.AvailableAt != nil && assignment.Data.AvailableAt.After(at) {
+			at = *assignment.Data.AvailableAt
+		}
+
+	default:
+		unlockAt := c.now
+		if !c.unlocked(id) {
+			for _, componentID := range subjectRelationships(subject).componentIDs {
+				componentPassAt, err := c.earliestPassAt(componentID, visiting)
+				if err != nil {
+					return time.Time{}, err
+				}
+				if componentPassAt.After(unlockAt) {
+					unlockAt = componentPassAt
+				}
+			}
+		}
+
+		schedule, err := srs.Data.ScheduleLesson(unlockAt)
+		if err != nil {
+			return time.Time{}, err
+		}
+		stage = schedule.Stage
+		at = *schedule.AvailableAt
+	}
+
+	for {
+		schedule, err := srs.Data.ScheduleReview(stage, 0, at)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if schedule.Stage >= srs.Data.PassingStagePosition {
+			break
+		}
+
+		stage = schedule.Stage
+		at = *schedule.AvailableAt
+	}
+
+	c.passAt[id] = at
+	return at, nil
+}
+
+func (c *levelUpCalculator) passed(id WKID) bool {
+	assignment, ok := c.assignments[id]
+	return ok && assignment.Data.PassedAt != nil
+}
+
+func (c *levelUpCalculator) unlocked(id WKID) bool {
+	assignment, ok := c.assignments[id]
+	return ok && assignment.Data.UnlockedAt != nil
+}
@@ -0,0 +1,180 @@
+package wanikaniapi_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	"github.com/brandur/wanikaniapi/wktesting"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewLevelUpStatus(t *testing.T) {
+	srs := mustLoadSRS(t, "spaced_repetition_system.json")
+
+	now := time.Date(2021, 3, 14, 10, 20, 0, 0, time.UTC)
+	hour := func(day, hour int) time.Time {
+		return time.Date(2021, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	radical1 := fixtureRadical(1, srs.ID, 5)
+	radical2 := fixtureRadical(2, srs.ID, 5)
+
+	subjects := []*wanikaniapi.Subject{radical1, radical2}
+	assignments := []*wanikaniapi.Assignment{
+		fixtureAssignment(1, 5, &now, nil, &now),
+		fixtureAssignment(2, 3, &now, timePtr(hour(14, 12)), nil),
+	}
+
+	// Seven passed kanji out of ten, so two more are needed.
+	for id := wanikaniapi.WKID(10); id < 17; id++ {
+		subjects = append(subjects, fixtureKanji(id, srs.ID, 5, 1))
+		assignments = append(assignments, fixtureAssignment(id, 5, &now, nil, &now))
+	}
+
+	// Unlocked at stage 4 and passes at its next review.
+	subjects = append(subjects, fixtureKanji(17, srs.ID, 5, 1))
+	assignments = append(assignments, fixtureAssignment(17, 4, &now, timePtr(hour(14, 15)), nil))
+
+	// Locked until radical 2 passes at 11:00 on the 16th, then needs a lesson
+	// and four reviews. Its other component is from an earlier level, so it's
+	// assumed to have passed and isn't blocking.
+	kanji18 := fixtureKanji(18, srs.ID, 5, 2, 99)
+	subjects = append(subjects, kanji18)
+
+	// Locked, but its only component has passed, so its lesson can be done
+	// right away.
+	subjects = append(subjects, fixtureKanji(19, srs.ID, 5, 1))
+
+	// Not counted: a hidden kanji and one from another level.
+	hidden := fixtureKanji(20, srs.ID, 5, 1)
+	hidden.KanjiData.HiddenAt = &now
+	otherLevel := fixtureKanji(21, srs.ID, 5, 1)
+	otherLevel.KanjiData.Level = 4
+	subjects = append(subjects, hidden, otherLevel)
+
+	params := &wanikaniapi.LevelUpStatusParams{
+		Assignments:             assignments,
+		Level:                   5,
+		Now:                     now,
+		SpacedRepetitionSystems: []*wanikaniapi.SpacedRepetitionSystem{srs},
+		Subjects:                subjects,
+	}
+
+	t.Run("InProgress", func(t *testing.T) {
+		status, err := wanikaniapi.NewLevelUpStatus(params)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 5, status.Level)
+		assert.Equal(t, 10, status.NumKanji)
+		assert.Equal(t, 7, status.NumKanjiPassed)
+		assert.Equal(t, 9, status.NumKanjiRequired)
+		assert.Equal(t, 2, status.NumKanjiNeeded)
+
+		assert.Equal(t, []*wanikaniapi.LevelUpBlockedKanji{
+			{
+				BlockingSubjectIDs: []wanikaniapi.WKID{2},
+				BlockingSubjects:   []*wanikaniapi.Subject{radical2},
+				Kanji:              kanji18,
+			},
+		}, status.BlockedKanji)
+
+		// Kanji pass at 15:00 on the 14th (17), 20:00 on the 17th (19), and
+		// 21:00 on the 19th (18). The second one is enough to level up.
+		assert.Equal(t, hour(17, 20), *status.EarliestLevelUpAt)
+	})
+
+	t.Run("EnoughPassed", func(t *testing.T) {
+		p := *params
+		p.Assignments = append(p.Assignments,
+			fixtureAssignment(17, 5, &now, nil, &now),
+			fixtureAssignment(19, 5, &now, nil, &now),
+		)
+
+		status, err := wanikaniapi.NewLevelUpStatus(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 9, status.NumKanjiPassed)
+		assert.Equal(t, 0, status.NumKanjiNeeded)
+		assert.Equal(t, now, *status.EarliestLevelUpAt)
+	})
+
+	t.Run("NoKanji", func(t *testing.T) {
+		p := *params
+		p.Level = 6
+
+		status, err := wanikaniapi.NewLevelUpStatus(&p)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, status.NumKanji)
+		assert.Nil(t, status.EarliestLevelUpAt)
+	})
+
+	t.Run("MissingSRS", func(t *testing.T) {
+		p := *params
+		p.SpacedRepetitionSystems = nil
+
+		_, err := wanikaniapi.NewLevelUpStatus(&p)
+		assert.EqualError(t, err, "spaced repetition system 1 of subject 17 not found")
+	})
+}
+
+func TestLevelUpStatusFetch(t *testing.T) {
+	client := wktesting.LocalClient()
+	client.Clock = fixedClock{time.Date(2021, 3, 14, 10, 20, 0, 0, time.UTC)}
+
+	user, err := ioutil.ReadFile("testdata/user.json")
+	assert.NoError(t, err)
+
+	srs, err := ioutil.ReadFile("testdata/spaced_repetition_system.json")
+	assert.NoError(t, err)
+
+	client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+		{StatusCode: http.StatusOK, Body: user},
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {"per_page": 1000, "next_url": null, "previous_url": null},
+			"total_count": 1,
+			"data": [
+				{"id": 440, "object": "kanji", "data": {"level": 5, "spaced_repetition_system_id": 1}}
+			]
+		}`)},
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {"per_page": 500, "next_url": null, "previous_url": null},
+			"total_count": 1,
+			"data": [
+				{"id": 1, "object": "assignment", "data": {
+					"subject_id": 440,
+					"srs_stage": 5,
+					"unlocked_at": "2021-03-01T00:00:00.000000Z",
+					"started_at": "2021-03-01T00:00:00.000000Z",
+					"passed_at": "2021-03-10T00:00:00.000000Z"
+				}}
+			]
+		}`)},
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {"per_page": 500, "next_url": null, "previous_url": null},
+			"total_count": 1,
+			"data": [` + string(srs) + `]
+		}`)},
+	}
+
+	status, err := client.LevelUpStatusFetch()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5, status.Level)
+	assert.Equal(t, 1, status.NumKanji)
+	assert.Equal(t, 1, status.NumKanjiPassed)
+	assert.Equal(t, 0, status.NumKanjiNeeded)
+	assert.Equal(t, client.Clock.Now(), *status.EarliestLevelUpAt)
+
+	assert.Equal(t, 4, len(client.RecordedRequests))
+	assert.Equal(t, "/v2/user", client.RecordedRequests[0].Path)
+	assert.Equal(t, "/v2/subjects", client.RecordedRequests[1].Path)
+	assert.Equal(t, "levels=5", client.RecordedRequests[1].Query)
+	assert.Equal(t, "/v2/assignments", client.RecordedRequests[2].Path)
+	assert.Equal(t, "levels=5", client.RecordedRequests[2].Query)
+	assert.Equal(t, "/v2/spaced_repetition_systems", client.RecordedRequests[3].Path)
+}
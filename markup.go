@@ -0,0 +1,246 @@
+package wanikaniapi
+
+import (
+	"html"
+	"strings"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// ParseMarkup parses WaniKani's markup as found in subject mnemonics and hints
+// like SubjectCommonData.MeaningMnemonic and SubjectKanjiData.ReadingHint.
+//
+// Parsing never fails. Tags that aren't known markup tags, and closing tags
+// without a matching opening tag, are kept as literal text. Tags left open at
+// the end of the input are closed implicitly. HTML entities in text like
+// `&amp;` are decoded.
+func ParseMarkup(s string) *Markup {
+	root := &MarkupNode{}
+	stack := []*MarkupNode{root}
+
+	var text strings.Builder
+	flushText := func() {
+		if text.Len() == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		top.Children = append(top.Children, &MarkupNode{Text: html.UnescapeString(text.String())})
+		text.Reset()
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] == '<' {
+			tag, closing, n := parseMarkupTag(s[i:])
+
+			switch {
+			case n == 0:
+				// Not a tag. Falls through to be written as text.
+
+			case !closing:
+				flushText()
+				node := &MarkupNode{Tag: tag}
+				top := stack[len(stack)-1]
+				top.Children = append(top.Children, node)
+				stack = append(stack, node)
+				i += n
+				continue
+
+			default:
+				if j := markupStackIndex(stack, tag); j > 0 {
+					flushText()
+					stack = stack[:j]
+					i += n
+					continue
+				}
+			}
+		}
+
+		text.WriteByte(s[i])
+		i++
+	}
+
+	flushText()
+
+	return &Markup{Nodes: root.Children}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Markup is parsed WaniKani markup. See ParseMarkup.
+type Markup struct {
+	// Nodes are the top-level nodes of the markup.
+	Nodes []*MarkupNode
+}
+
+// ANSI renders the markup as text for a terminal, coloring subject types
+// similarly to how WaniKani does and emphasizing readings and meanings.
+func (m *Markup) ANSI() string {
+	var sb strings.Builder
+	renderMarkupANSI(&sb, m.Nodes, nil)
+	return sb.String()
+}
+
+// HTML renders the markup as HTML that's safe to embed in a page. Text is
+// escaped, and each tag becomes a span with a class of the tag's name like
+// `<span class="kanji">`, except for MarkupTagJapanese, which becomes
+// `<span lang="ja">`.
+func (m *Markup) HTML() string {
+	var sb strings.Builder
+	renderMarkupHTML(&sb, m.Nodes)
+	return sb.String()
+}
+
+// PlainText renders the markup as text with all tags removed.
+func (m *Markup) PlainText() string {
+	var sb strings.Builder
+	renderMarkupPlainText(&sb, m.Nodes)
+	return sb.String()
+}
+
+// MarkupNode is a node in parsed markup. A node is either text, in which case
+// Text is set, or a tag, in which case Tag is set and the tag's contents are
+// in Children.
+type MarkupNode struct {
+	// Children are the nodes within a tag. Always empty for text.
+	Children []*MarkupNode
+
+	// Tag is the node's tag. Empty for text.
+	Tag MarkupTag
+
+	// Text is the node's text. Empty for tags.
+	Text string
+}
+
+// MarkupTag is a tag in WaniKani's markup.
+type MarkupTag string
+
+// All known markup tags.
+const (
+	MarkupTagJapanese   MarkupTag = "ja"
+	MarkupTagKanji      MarkupTag = "kanji"
+	MarkupTagMeaning    MarkupTag = "meaning"
+	MarkupTagRadical    MarkupTag = "radical"
+	MarkupTagReading    MarkupTag = "reading"
+	MarkupTagVocabulary MarkupTag = "vocabulary"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+const ansiReset = "\x1b[0m"
+
+// markupANSIStyles are the ANSI escape sequences used to style each tag.
+var markupANSIStyles = map[MarkupTag]string{
+	MarkupTagJapanese:   "",
+	MarkupTagKanji:      "\x1b[35m", // magenta
+	MarkupTagMeaning:    "\x1b[1m",  // bold
+	MarkupTagRadical:    "\x1b[34m", // blue
+	MarkupTagReading:    "\x1b[1m",  // bold
+	MarkupTagVocabulary: "\x1b[95m", // bright magenta
+}
+
+// markupStackIndex returns the index of the innermost open node with the given
+// tag, or zero if there is none. Index zero is the root, which has no tag.
+func markupStackIndex(stack []*MarkupNode, tag MarkupTag) int {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].Tag == tag {
+			return i
+		}
+	}
+	return 0
+}
+
+// parseMarkupTag parses a known markup tag like `<kanji>` or `</kanji>` at
+// the start of s, returning the tag, whether it's a closing tag, and its
+// length. The length is zero if s doesn't start with a known tag.
+func parseMarkupTag(s string) (MarkupTag, bool, int) {
+	end := strings.IndexByte(s, '>')
+	if end == -1 {
+		return "", false, 0
+	}
+
+	name := s[1:end]
+	closing := strings.HasPrefix(name, "/")
+	if closing {
+		name = name[1:]
+	}
+
+	tag := MarkupTag(name)
+	if _, ok := markupANSIStyles[tag]; !ok {
+		return "", false, 0
+	}
+
+	return tag, closing, end + 1
+}
+
+func renderMarkupANSI(sb *strings.Builder, nodes []*MarkupNode, styles []string) {
+	for _, node := range nodes {
+		if node.Tag == "" {
+			sb.WriteString(node.Text)
+			continue
+		}
+
+		style := markupANSIStyles[node.Tag]
+		sb.WriteString(style)
+		renderMarkupANSI(sb, node.Children, append(styles, style))
+
+		// Terminals can't pop a single style, so reset them all and then
+		// restore those of enclosing tags.
+		if style != "" {
+			sb.WriteString(ansiReset)
+			for _, outer := range styles {
+				sb.WriteString(outer)
+			}
+		}
+	}
+}
+
+func renderMarkupHTML(sb *strings.Builder, nodes []*MarkupNode) {
+	for _, node := range nodes {
+		if node.Tag == "" {
+			sb.WriteString(html.EscapeString(node.Text))
+			continue
+		}
+
+		if node.Tag == MarkupTagJapanese {
+			sb.WriteString(`<span lang="ja">`)
+		} else {
+			sb.WriteString(`<span class="` + string(node.Tag) + `">`)
+		}
+		renderMarkupHTML(sb, node.Children)
+		sb.WriteString("</span>")
+	}
+}
+
+func renderMarkupPlainText(sb *strings.Builder, nodes []*MarkupNode) {
+	for _, node := range nodes {
+		if node.Tag == "" {
+			sb.WriteString(node.Text)
+			continue
+		}
+		renderMarkupPlainText(sb, node.Children)
+	}
+}
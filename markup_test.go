@@ -0,0 +1,150 @@
+package wanikaniapi_test
+
+import (
+	"testing"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestParseMarkup(t *testing.T) {
+	text := func(s string) *wanikaniapi.MarkupNode {
+		return &wanikaniapi.MarkupNode{Text: s}
+	}
+	tag := func(tag wanikaniapi.MarkupTag, children ...*wanikaniapi.MarkupNode) *wanikaniapi.MarkupNode {
+		return &wanikaniapi.MarkupNode{Tag: tag, Children: children}
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []*wanikaniapi.MarkupNode
+	}{
+		{
+			name:     "Empty",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "PlainText",
+			input:    "no markup here",
+			expected: []*wanikaniapi.MarkupNode{text("no markup here")},
+		},
+		{
+			name:  "Tags",
+			input: "the <radical>ground</radical> and <kanji>One</kanji>.",
+			expected: []*wanikaniapi.MarkupNode{
+				text("the "),
+				tag(wanikaniapi.MarkupTagRadical, text("ground")),
+				text(" and "),
+				tag(wanikaniapi.MarkupTagKanji, text("One")),
+				text("."),
+			},
+		},
+		{
+			name:  "Nested",
+			input: "<reading><ja>いち</ja> (ichi)</reading>",
+			expected: []*wanikaniapi.MarkupNode{
+				tag(wanikaniapi.MarkupTagReading,
+					tag(wanikaniapi.MarkupTagJapanese, text("いち")),
+					text(" (ichi)"),
+				),
+			},
+		},
+		{
+			name:  "UnknownTagsAreText",
+			input: "<b>bold</b> <vocabulary>one</vocabulary>",
+			expected: []*wanikaniapi.MarkupNode{
+				text("<b>bold</b> "),
+				tag(wanikaniapi.MarkupTagVocabulary, text("one")),
+			},
+		},
+		{
+			name:  "UnmatchedClosingTagIsText",
+			input: "a</kanji>b",
+			expected: []*wanikaniapi.MarkupNode{
+				text("a</kanji>b"),
+			},
+		},
+		{
+			name:  "UnclosedTag",
+			input: "a <meaning>one",
+			expected: []*wanikaniapi.MarkupNode{
+				text("a "),
+				tag(wanikaniapi.MarkupTagMeaning, text("one")),
+			},
+		},
+		{
+			name:  "ClosingOuterTagClosesInner",
+			input: "<kanji>a<meaning>b</kanji>c",
+			expected: []*wanikaniapi.MarkupNode{
+				tag(wanikaniapi.MarkupTagKanji,
+					text("a"),
+					tag(wanikaniapi.MarkupTagMeaning, text("b")),
+				),
+				text("c"),
+			},
+		},
+		{
+			name:  "LoneAngleBrackets",
+			input: "1 < 2 <kanji>x</kanji> > 0",
+			expected: []*wanikaniapi.MarkupNode{
+				text("1 < 2 "),
+				tag(wanikaniapi.MarkupTagKanji, text("x")),
+				text(" > 0"),
+			},
+		},
+		{
+			name:  "Entities",
+			input: "salt &amp; pepper",
+			expected: []*wanikaniapi.MarkupNode{
+				text("salt & pepper"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, wanikaniapi.ParseMarkup(tc.input).Nodes)
+		})
+	}
+}
+
+func TestMarkupRender(t *testing.T) {
+	subject := mustLoadSubject(t, "subject_kanji.json")
+	markup := wanikaniapi.ParseMarkup(subject.KanjiData.MeaningMnemonic)
+
+	t.Run("PlainText", func(t *testing.T) {
+		assert.Equal(t,
+			"Lying on the ground is something that looks just like the ground, the number One.",
+			markup.PlainText())
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		assert.Equal(t,
+			`Lying on the <span class="radical">ground</span> is something that looks just like the ground, the number <span class="kanji">One</span>.`,
+			markup.HTML())
+
+		// Text is escaped so that markup can't inject HTML.
+		markup := wanikaniapi.ParseMarkup(`<reading><ja>いち</ja></reading> <script>alert("&amp;")</script>`)
+		assert.Equal(t,
+			`<span class="reading"><span lang="ja">いち</span></span> &lt;script&gt;alert(&#34;&amp;&#34;)&lt;/script&gt;`,
+			markup.HTML())
+	})
+
+	t.Run("ANSI", func(t *testing.T) {
+		assert.Equal(t,
+			"Lying on the \x1b[34mground\x1b[0m is something that looks just like the ground, the number \x1b[35mOne\x1b[0m.",
+			markup.ANSI())
+
+		// Styles of enclosing tags are restored after a nested tag.
+		markup := wanikaniapi.ParseMarkup("<vocabulary>a <reading>b</reading> c</vocabulary> d")
+		assert.Equal(t,
+			"\x1b[95ma \x1b[1mb\x1b[0m\x1b[95m c\x1b[0m d",
+			markup.ANSI())
+
+		// Japanese text isn't styled.
+		markup = wanikaniapi.ParseMarkup("<ja>いち</ja>")
+		assert.Equal(t, "いち", markup.ANSI())
+	})
+}
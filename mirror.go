@@ -0,0 +1,391 @@
+package wanikaniapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewAssetMirror returns a new mirror for subject assets.
+func NewAssetMirror(config *AssetMirrorConfig) *AssetMirror {
+	return &AssetMirror{
+		Dir:            config.Dir,
+		HTTPClient:     config.HTTPClient,
+		Logger:         config.Logger,
+		MaxConcurrency: config.MaxConcurrency,
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// AssetManifestFilename is the name of the manifest file that AssetMirror
+// writes into its directory.
+const AssetManifestFilename = "manifest.json"
+
+// AssetManifest maps the URLs of mirrored assets to local files. It's written
+// as JSON to AssetManifestFilename in an AssetMirror's directory.
+type AssetManifest struct {
+	// Assets are mirrored assets keyed by URL.
+	Assets map[string]*AssetManifestEntry `json:"assets"`
+}
+
+// AssetManifestEntry describes a single mirrored asset.
+type AssetManifestEntry struct {
+	// ContentType is the content type of the asset like ContentTypeImageSVG.
+	ContentType string `json:"content_type"`
+
+	// ETag is the value of the ETag header returned with the asset, if any.
+	// It's used to check whether the asset has changed on later runs.
+	ETag string `json:"etag,omitempty"`
+
+	// LastModified is the value of the Last-Modified header returned with the
+	// asset, if any. It's used to check whether the asset has changed on later
+	// runs.
+	LastModified string `json:"last_modified,omitempty"`
+
+	// Path is the path of the local file relative to the mirror's directory.
+	Path string `json:"path"`
+
+	// SHA256 is the hex-encoded SHA-256 hash of the asset's contents, which
+	// also names its local file.
+	SHA256 string `json:"sha256"`
+
+	// Size is the size of the asset in bytes.
+	Size int64 `json:"size"`
+}
+
+// AssetMirror keeps local copies of the assets of subjects: the character
+// images of radicals and the pronounciation audio of vocabulary and kana
+// vocabulary.
+//
+// Files are content-addressed, named by the SHA-256 hash of their contents,
+// so identical assets at different URLs are stored once. A manifest mapping
+// URLs to files is written to AssetManifestFilename. On later runs, assets
+// already in the manifest are only downloaded again if the server reports that
+// they've changed, which is checked with a conditional request when the server
+// originally sent an ETag or Last-Modified header.
+type AssetMirror struct {
+	// Dir is the directory that assets and the manifest are written to. It's
+	// created if it doesn't exist.
+	Dir string
+
+	// HTTPClient is the HTTP client used to download assets. Assets are served
+	// from WaniKani's CDN and don't need an API token. Defaults to a new
+	// http.Client if nil.
+	HTTPClient *http.Client
+
+	// Logger is the logger to send logging messages to. Defaults to a
+	// LeveledLogger that only shows errors if nil.
+	Logger LeveledLoggerInterface
+
+	// MaxConcurrency is the maximum number of assets downloaded at once.
+	// Defaults to 4 if it's zero or negative.
+	MaxConcurrency int
+}
+
+// Mirror downloads the assets of the given subjects and writes a manifest
+// mapping their URLs to local files.
+//
+// The manifest includes only assets of the given subjects. A failure to
+// download one asset doesn't stop others from being downloaded. The manifest
+// is written even if some downloads failed, keeping any previous entries for
+// those assets, and the first failure is returned as an error along with the
+// result.
+func (m *AssetMirror) Mirror(subjects []*Subject) (*AssetMirrorResult, error) {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating mirror directory: %w", err)
+	}
+
+	previous, err := m.readManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	assets := subjectAssets(subjects)
+	result := &AssetMirrorResult{
+		Manifest: &AssetManifest{Assets: make(map[string]*AssetManifestEntry, len(assets))},
+	}
+
+	httpClient := m.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	logger := m.Logger
+	if logger == nil {
+		logger = &LeveledLogger{Level: LevelError}
+	}
+
+	maxConcurrency := m.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultAssetMirrorMaxConcurrency
+	}
+
+	var firstErr error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrency)
+
+	for _, asset := range assets {
+		asset := asset
+		previousEntry := previous.Assets[asset.url]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			entry, downloaded, err := m.mirrorOne(asset, previousEntry, httpClient, logger)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case err != nil:
+				logger.Errorf("Error mirroring asset; url=%v: %v", asset.url, err)
+				result.NumFailed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				if previousEntry != nil {
+					result.Manifest.Assets[asset.url] = previousEntry
+				}
+
+			case downloaded:
+				result.NumDownloaded++
+				result.Manifest.Assets[asset.url] = entry
+
+			default:
+				result.NumUnchanged++
+				result.Manifest.Assets[asset.url] = entry
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if err := m.writeManifest(result.Manifest); err != nil {
+		return result, err
+	}
+
+	return result, firstErr
+}
+
+// AssetMirrorConfig specifies configuration for an AssetMirror.
+type AssetMirrorConfig struct {
+	// Dir is the directory that assets and the manifest are written to.
+	Dir string
+
+	// HTTPClient is an HTTP client to use for downloads. Defaults to a new
+	// http.Client.
+	HTTPClient *http.Client
+
+	// Logger is the logger to send logging messages to. Defaults to a
+	// LeveledLogger that only shows errors.
+	Logger LeveledLoggerInterface
+
+	// MaxConcurrency is the maximum number of assets downloaded at once.
+	// Defaults to 4.
+	MaxConcurrency int
+}
+
+// AssetMirrorResult is the result of a run of AssetMirror.Mirror.
+type AssetMirrorResult struct {
+	// Manifest is the manifest that was written.
+	Manifest *AssetManifest
+
+	// NumDownloaded is the number of assets that were new or had changed and
+	// were downloaded.
+	NumDownloaded int
+
+	// NumFailed is the number of assets that couldn't be mirrored.
+	NumFailed int
+
+	// NumUnchanged is the number of assets that were already mirrored and
+	// hadn't changed.
+	NumUnchanged int
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+const defaultAssetMirrorMaxConcurrency = 4
+
+// subjectAsset is an asset of a subject that can be mirrored.
+type subjectAsset struct {
+	contentType string
+	url         string
+}
+
+// mirrorOne mirrors a single asset, returning its manifest entry and whether
+// it was downloaded. If the asset was mirrored before and the server reports
+// that it hasn't changed, previous is returned as is.
+func (m *AssetMirror) mirrorOne(asset *subjectAsset, previous *AssetManifestEntry, httpClient *http.Client, logger LeveledLoggerInterface) (*AssetManifestEntry, bool, error) {
+	if previous != nil {
+		if _, err := os.Stat(filepath.Join(m.Dir, previous.Path)); err != nil {
+			// The file is gone, so download it again without revalidating.
+			previous = nil
+		} else if previous.ETag == "" && previous.LastModified == "" {
+			// Without validators there's no way to check for a change, so
+			// trust the existing copy.
+			return previous, false, nil
+		}
+	}
+
+	req, err := http.NewRequest("GET", asset.url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("error creating asset request: %w", err)
+	}
+
+	if previous != nil {
+		if previous.ETag != "" {
+			req.Header.Set("If-None-Match", previous.ETag)
+		}
+		if previous.LastModified != "" {
+			req.Header.Set("If-Modified-Since", previous.LastModified)
+		}
+	}
+
+	logger.Debugf("Requesting asset; url=%v", asset.url)
+
+	// Files are named by the hash of their contents.
+	download, err := downloadFile(httpClient, req, m.Dir, "asset", func(sum string) string {
+		return filepath.Join(m.Dir, sum+contentTypeExtensions[asset.contentType])
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if download.notModified {
+		return previous, false, nil
+	}
+
+	return &AssetManifestEntry{
+		ContentType:  asset.contentType,
+		ETag:         download.header.Get("ETag"),
+		LastModified: download.header.Get("Last-Modified"),
+		Path:         filepath.Base(download.path),
+		SHA256:       download.sha256,
+		Size:         download.size,
+	}, true, nil
+}
+
+func (m *AssetMirror) readManifest() (*AssetManifest, error) {
+	manifest := &AssetManifest{}
+
+	data, err := ioutil.ReadFile(filepath.Join(m.Dir, AssetManifestFilename))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading asset manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("error decoding asset manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func (m *AssetMirror) writeManifest(manifest *AssetManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding asset manifest: %w", err)
+	}
+
+	// Write to a temporary file and rename so that a manifest is never left
+	// half written.
+	tmpFile, err := ioutil.TempFile(m.Dir, ".manifest-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary manifest file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing asset manifest: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), filepath.Join(m.Dir, AssetManifestFilename)); err != nil {
+		return fmt.Errorf("error moving asset manifest into place: %w", err)
+	}
+
+	return nil
+}
+
+// subjectAssets returns the unique assets of the given subjects, sorted by
+// URL.
+func subjectAssets(subjects []*Subject) []*subjectAsset {
+	byURL := make(map[string]*subjectAsset)
+
+	addAudios := func(audios []*SubjectVocabularyPronounciationAudio) {
+		for _, audio := range audios {
+			byURL[audio.URL] = &subjectAsset{contentType: audio.ContentType, url: audio.URL}
+		}
+	}
+
+	for _, subject := range subjects {
+		switch {
+		case subject.KanaVocabularyData != nil:
+			addAudios(subject.KanaVocabularyData.PronounciationAudios)
+
+		case subject.RadicalData != nil:
+			for _, image := range subject.RadicalData.CharacterImages {
+				byURL[image.URL] = &subjectAsset{contentType: image.ContentType, url: image.URL}
+			}
+
+		case subject.VocabularyData != nil:
+			addAudios(subject.VocabularyData.PronounciationAudios)
+		}
+	}
+
+	urls := make([]string, 0, len(byURL))
+	for url := range byURL {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	assets := make([]*subjectAsset, len(urls))
+	for i, url := range urls {
+		assets[i] = byURL[url]
+	}
+
+	return assets
+}
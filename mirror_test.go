@@ -0,0 +1,302 @@
+package wanikaniapi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestAssetMirror(t *testing.T) {
+	server := newAssetServer()
+	defer server.Close()
+
+	server.setAsset("/radical.svg", "<svg/>")
+	server.setAsset("/radical.png", "png bytes")
+	server.setAsset("/ichi.mp3", "ichi mpeg")
+	server.setAsset("/ichi.ogg", "ichi ogg")
+	server.setAsset("/oyatsu.mp3", "ichi mpeg") // same contents as ichi.mp3
+
+	dir, err := ioutil.TempDir("", "wanikaniapi-mirror")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mirror := wanikaniapi.NewAssetMirror(&wanikaniapi.AssetMirrorConfig{
+		Dir:            dir,
+		MaxConcurrency: 2,
+	})
+
+	subjects := []*wanikaniapi.Subject{
+		{RadicalData: &wanikaniapi.SubjectRadicalData{
+			CharacterImages: []*wanikaniapi.SubjectRadicalCharacterImage{
+				{ContentType: wanikaniapi.ContentTypeImageSVG, URL: server.URL + "/radical.svg"},
+				{ContentType: wanikaniapi.ContentTypeImagePNG, URL: server.URL + "/radical.png"},
+			},
+		}},
+		{KanjiData: &wanikaniapi.SubjectKanjiData{}},
+		{VocabularyData: &wanikaniapi.SubjectVocabularyData{
+			PronounciationAudios: []*wanikaniapi.SubjectVocabularyPronounciationAudio{
+				{ContentType: wanikaniapi.ContentTypeAudioMPEG, URL: server.URL + "/ichi.mp3"},
+				{ContentType: wanikaniapi.ContentTypeAudioOgg, URL: server.URL + "/ichi.ogg"},
+			},
+		}},
+		{KanaVocabularyData: &wanikaniapi.SubjectKanaVocabularyData{
+			PronounciationAudios: []*wanikaniapi.SubjectVocabularyPronounciationAudio{
+				{ContentType: wanikaniapi.ContentTypeAudioMPEG, URL: server.URL + "/oyatsu.mp3"},
+			},
+		}},
+	}
+
+	t.Run("Initial", func(t *testing.T) {
+		result, err := mirror.Mirror(subjects)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, result.NumDownloaded)
+		assert.Equal(t, 0, result.NumUnchanged)
+		assert.Equal(t, 5, server.numDownloads())
+		assert.True(t, server.maxInFlight() <= 2)
+
+		manifest := readAssetManifest(t, dir)
+		assert.Equal(t, result.Manifest, manifest)
+		assert.Equal(t, 5, len(manifest.Assets))
+
+		entry := manifest.Assets[server.URL+"/radical.svg"]
+		assert.Equal(t, wanikaniapi.ContentTypeImageSVG, entry.ContentType)
+		assert.Equal(t, entry.SHA256+".svg", entry.Path)
+		assert.Equal(t, int64(6), entry.Size)
+		assertFileContents(t, "<svg/>", filepath.Join(dir, entry.Path))
+
+		// Content-addressed, so identical contents share a file.
+		assert.Equal(t, manifest.Assets[server.URL+"/ichi.mp3"].Path,
+			manifest.Assets[server.URL+"/oyatsu.mp3"].Path)
+		assertFileContents(t, "ichi mpeg", filepath.Join(dir, manifest.Assets[server.URL+"/ichi.mp3"].Path))
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		server.reset()
+
+		result, err := mirror.Mirror(subjects)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, result.NumDownloaded)
+		assert.Equal(t, 5, result.NumUnchanged)
+		assert.Equal(t, 0, server.numDownloads())
+	})
+
+	t.Run("ChangedAndNew", func(t *testing.T) {
+		server.reset()
+
+		oldPath := readAssetManifest(t, dir).Assets[server.URL+"/radical.png"].Path
+
+		server.setAsset("/radical.png", "new png bytes")
+		server.setAsset("/ni.mp3", "ni mpeg")
+
+		subjects := append(subjects, &wanikaniapi.Subject{
+			VocabularyData: &wanikaniapi.SubjectVocabularyData{
+				PronounciationAudios: []*wanikaniapi.SubjectVocabularyPronounciationAudio{
+					{ContentType: wanikaniapi.ContentTypeAudioMPEG, URL: server.URL + "/ni.mp3"},
+				},
+			},
+		})
+
+		result, err := mirror.Mirror(subjects)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.NumDownloaded)
+		assert.Equal(t, 4, result.NumUnchanged)
+		assert.Equal(t, 2, server.numDownloads())
+
+		manifest := readAssetManifest(t, dir)
+		assert.Equal(t, 6, len(manifest.Assets))
+
+		newPath := manifest.Assets[server.URL+"/radical.png"].Path
+		assert.NotEqual(t, oldPath, newPath)
+		assertFileContents(t, "new png bytes", filepath.Join(dir, newPath))
+		assertFileContents(t, "ni mpeg", filepath.Join(dir, manifest.Assets[server.URL+"/ni.mp3"].Path))
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		server.reset()
+
+		entry := readAssetManifest(t, dir).Assets[server.URL+"/ichi.ogg"]
+		assert.NoError(t, os.Remove(filepath.Join(dir, entry.Path)))
+
+		result, err := mirror.Mirror(subjects)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.NumDownloaded)
+		assertFileContents(t, "ichi ogg", filepath.Join(dir, entry.Path))
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		server.reset()
+
+		previousEntry := readAssetManifest(t, dir).Assets[server.URL+"/radical.svg"]
+		server.failPath("/radical.svg")
+
+		result, err := mirror.Mirror(subjects)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected status 500")
+		assert.Equal(t, 1, result.NumFailed)
+		assert.Equal(t, 4, result.NumUnchanged)
+
+		// The previous entry for the failed asset is kept.
+		manifest := readAssetManifest(t, dir)
+		assert.Equal(t, previousEntry, manifest.Assets[server.URL+"/radical.svg"])
+	})
+}
+
+func TestAssetMirrorLiteral(t *testing.T) {
+	server := newAssetServer()
+	defer server.Close()
+
+	server.setAsset("/radical.svg", "<svg/>")
+
+	subjects := []*wanikaniapi.Subject{
+		{RadicalData: &wanikaniapi.SubjectRadicalData{
+			CharacterImages: []*wanikaniapi.SubjectRadicalCharacterImage{
+				{ContentType: wanikaniapi.ContentTypeImageSVG, URL: server.URL + "/radical.svg"},
+			},
+		}},
+	}
+
+	// HTTPClient and Logger are nil and fall back to defaults, and an unset
+	// or negative MaxConcurrency falls back to its default instead of
+	// blocking.
+	for _, maxConcurrency := range []int{0, -1} {
+		dir, err := ioutil.TempDir("", "wanikaniapi-mirror")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		mirror := &wanikaniapi.AssetMirror{Dir: dir, MaxConcurrency: maxConcurrency}
+
+		result, err := mirror.Mirror(subjects)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.NumDownloaded)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// assetServer is a test server for assets that supports ETags and tracks the
+// number of downloads and concurrent requests.
+type assetServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	assets       map[string]string
+	downloads    int
+	failing      map[string]bool
+	inFlight     int
+	peakInFlight int
+}
+
+func newAssetServer() *assetServer {
+	s := &assetServer{
+		assets:  make(map[string]string),
+		failing: make(map[string]bool),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *assetServer) failPath(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing[path] = true
+}
+
+func (s *assetServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.peakInFlight {
+		s.peakInFlight = s.inFlight
+	}
+	body, ok := s.assets[r.URL.Path]
+	failing := s.failing[r.URL.Path]
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	// Give other requests a chance to overlap.
+	time.Sleep(10 * time.Millisecond)
+
+	switch {
+	case failing:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	etag := `"` + body + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.mu.Lock()
+	s.downloads++
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", etag)
+	_, _ = w.Write([]byte(body))
+}
+
+func (s *assetServer) maxInFlight() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peakInFlight
+}
+
+func (s *assetServer) numDownloads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.downloads
+}
+
+func (s *assetServer) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downloads = 0
+	s.failing = make(map[string]bool)
+	s.peakInFlight = 0
+}
+
+func (s *assetServer) setAsset(path, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assets[path] = body
+}
+
+func assertFileContents(t *testing.T, expected, path string) {
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
+
+func readAssetManifest(t *testing.T, dir string) *wanikaniapi.AssetManifest {
+	data, err := ioutil.ReadFile(filepath.Join(dir, wanikaniapi.AssetManifestFilename))
+	assert.NoError(t, err)
+
+	manifest := &wanikaniapi.AssetManifest{}
+	assert.NoError(t, json.Unmarshal(data, manifest))
+	return manifest
+}
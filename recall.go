@@ -0,0 +1,325 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewRecallModel fits a memory model to a user's review history, estimating
+// the stability and difficulty of every subject they've studied so that the
+// probability of recalling it at any time can be predicted.
+//
+// The model is like FSRS (Free Spaced Repetition Scheduler). Each subject's
+// history is replayed in order: an assignment's StartedAt is its lesson, which
+// starts the subject fresh as if it was answered correctly, and each review
+// afterward either succeeds, if it was answered without any incorrect
+// answers, or lapses. Starting fresh at the lesson means that reviews from
+// before a reset don't affect the subject.
+//
+// Fitting is deterministic, so the same history always produces the same
+// model.
+func NewRecallModel(params *RecallModelParams) (*RecallModel, error) {
+	weights := params.Weights
+	if weights == nil {
+		weights = RecallModelDefaultWeights
+	}
+	if len(weights) != len(RecallModelDefaultWeights) {
+		return nil, fmt.Errorf("RecallModelParams.Weights must have %v weights", len(RecallModelDefaultWeights))
+	}
+
+	model := &RecallModel{
+		Items:   make(map[WKID]*RecallItem),
+		Weights: append([]float64(nil), weights...),
+	}
+
+	var events []*recallEvent
+	for _, assignment := range params.Assignments {
+		if assignment.Data == nil || assignment.Data.StartedAt == nil {
+			continue
+		}
+		events = append(events, &recallEvent{
+			at:        *assignment.Data.StartedAt,
+			lesson:    true,
+			subjectID: assignment.Data.SubjectID,
+		})
+	}
+	for _, review := range params.Reviews {
+		if review.Data == nil {
+			continue
+		}
+		events = append(events, &recallEvent{
+			at:        review.Data.CreatedAt,
+			passed:    review.Data.IncorrectMeaningAnswers+review.Data.IncorrectReadingAnswers == 0,
+			subjectID: review.Data.SubjectID,
+		})
+	}
+
+	// Lessons sort before reviews at the same time so that a review can't be
+	// lost to a lesson that restarts its subject.
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].lesson && !events[j].lesson
+	})
+
+	for _, event := range events {
+		if event.lesson {
+			model.Items[event.subjectID] = model.newItem(event.subjectID, true, event.at)
+			continue
+		}
+		model.review(event.subjectID, event.passed, event.at)
+	}
+
+	return model, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// RecallModelDefaultWeights are the default weights of a RecallModel, which
+// are FSRS-4.5's defaults.
+var RecallModelDefaultWeights = []float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+	0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// RecallItem is the memory state of a single subject in a RecallModel.
+type RecallItem struct {
+	// Difficulty is how hard the subject is to remember, from 1 to 10.
+	Difficulty float64 `json:"difficulty"`
+
+	// LastReviewedAt is when the subject was last reviewed, or when its lesson
+	// was done if it hasn't been reviewed since.
+	LastReviewedAt time.Time `json:"last_reviewed_at"`
+
+	// NumLapses is the number of reviews since the lesson that were answered
+	// incorrectly.
+	NumLapses int `json:"num_lapses"`
+
+	// NumReviews is the number of reviews since the lesson.
+	NumReviews int `json:"num_reviews"`
+
+	// Stability is the number of days after LastReviewedAt that the
+	// probability of recalling the subject falls to 90%.
+	Stability float64 `json:"stability"`
+
+	// SubjectID is the ID of the subject.
+	SubjectID WKID `json:"subject_id"`
+}
+
+// Recall returns the probability of recalling the subject at the given time,
+// from 0 to 1.
+func (i *RecallItem) Recall(at time.Time) float64 {
+	elapsedDays := at.Sub(i.LastReviewedAt).Hours() / 24
+	if elapsedDays < 0 {
+		elapsedDays = 0
+	}
+	return math.Pow(1+recallFactor*elapsedDays/i.Stability, recallDecay)
+}
+
+// RecallModel is a memory model of a user's subjects fitted to their review
+// history. It serializes to and from JSON so that it can be saved and updated
+// with new reviews later. See NewRecallModel.
+type RecallModel struct {
+	// Items are the memory states of subjects keyed by subject ID.
+	Items map[WKID]*RecallItem `json:"items"`
+
+	// Weights are the model's weights. See RecallModelDefaultWeights.
+	Weights []float64 `json:"weights"`
+}
+
+// Predict returns the probability of recalling a subject at the given time,
+// from 0 to 1. Returns an error if the model has no history for the subject.
+func (m *RecallModel) Predict(subjectID WKID, at time.Time) (float64, error) {
+	item, ok := m.Items[subjectID]
+	if !ok {
+		return 0, fmt.Errorf("no recall history for subject %v", subjectID)
+	}
+	return item.Recall(at), nil
+}
+
+// RankUpcoming predicts the probability of recalling each assignment that's
+// available for review by until, at the time it becomes available, and
+// returns them ranked from most at risk of being forgotten to least.
+// Assignments that aren't available for review or that the model has no
+// history for are skipped.
+func (m *RecallModel) RankUpcoming(assignments []*Assignment, until time.Time) []*RecallPrediction {
+	var predictions []*RecallPrediction
+	for _, assignment := range assignments {
+		data := assignment.Data
+		if data == nil || data.Hidden || data.AvailableAt == nil || data.AvailableAt.After(until) {
+			continue
+		}
+
+		item, ok := m.Items[data.SubjectID]
+		if !ok {
+			continue
+		}
+
+		predictions = append(predictions, &RecallPrediction{
+			Assignment: assignment,
+			At:         *data.AvailableAt,
+			Item:       item,
+			Recall:     item.Recall(*data.AvailableAt),
+		})
+	}
+
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].Recall != predictions[j].Recall {
+			return predictions[i].Recall < predictions[j].Recall
+		}
+		return predictions[i].Item.SubjectID < predictions[j].Item.SubjectID
+	})
+
+	return predictions
+}
+
+// Update updates the model with a new review, as if it had been part of the
+// history that the model was fitted to. Reviews must be applied in the order
+// they were created.
+func (m *RecallModel) Update(review *Review) {
+	if review.Data == nil {
+		return
+	}
+	m.review(review.Data.SubjectID,
+		review.Data.IncorrectMeaningAnswers+review.Data.IncorrectReadingAnswers == 0,
+		review.Data.CreatedAt)
+}
+
+// RecallModelParams are parameters for NewRecallModel.
+type RecallModelParams struct {
+	// Assignments are the user's assignments, which are needed for when their
+	// lessons were done.
+	Assignments []*Assignment
+
+	// Reviews are the user's reviews.
+	Reviews []*Review
+
+	// Weights are the model's weights. Defaults to RecallModelDefaultWeights.
+	Weights []float64
+}
+
+// RecallPrediction is the predicted probability of recalling an upcoming
+// review. See RecallModel.RankUpcoming.
+type RecallPrediction struct {
+	// Assignment is the assignment to be reviewed.
+	Assignment *Assignment
+
+	// At is when the assignment becomes available for review.
+	At time.Time
+
+	// Item is the memory state of the assignment's subject.
+	Item *RecallItem
+
+	// Recall is the probability of recalling the subject at At, from 0 to 1.
+	Recall float64
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// recallDecay and recallFactor shape the forgetting curve so that recall is
+// 90% after exactly one stability.
+const (
+	recallDecay  = -0.5
+	recallFactor = 19.0 / 81.0
+)
+
+// FSRS grades. WaniKani reviews either pass or fail, so only these two are
+// used.
+const (
+	recallGradeAgain = 1
+	recallGradeGood  = 3
+)
+
+type recallEvent struct {
+	at        time.Time
+	lesson    bool
+	passed    bool
+	subjectID WKID
+}
+
+func (m *RecallModel) initialDifficulty(grade int) float64 {
+	return clampDifficulty(m.Weights[4] - float64(grade-recallGradeGood)*m.Weights[5])
+}
+
+func (m *RecallModel) newItem(subjectID WKID, passed bool, at time.Time) *RecallItem {
+	grade := recallGrade(passed)
+	return &RecallItem{
+		Difficulty:     m.initialDifficulty(grade),
+		LastReviewedAt: at,
+		Stability:      m.Weights[grade-1],
+		SubjectID:      subjectID,
+	}
+}
+
+// review applies a review to a subject, starting it if it's the first that the
+// model has seen.
+func (m *RecallModel) review(subjectID WKID, passed bool, at time.Time) {
+	item, ok := m.Items[subjectID]
+	if !ok {
+		item = m.newItem(subjectID, passed, at)
+		m.Items[subjectID] = item
+	} else {
+		w := m.Weights
+		grade := recallGrade(passed)
+		recall := item.Recall(at)
+
+		if passed {
+			item.Stability *= 1 + math.Exp(w[8])*(11-item.Difficulty)*math.Pow(item.Stability, -w[9])*(math.Exp(w[10]*(1-recall))-1)
+		} else {
+			item.Stability = math.Min(item.Stability,
+				w[11]*math.Pow(item.Difficulty, -w[12])*(math.Pow(item.Stability+1, w[13])-1)*math.Exp(w[14]*(1-recall)))
+		}
+
+		// Difficulty moves with the grade, but reverts a little toward the
+		// initial difficulty of a correct answer each time.
+		difficulty := item.Difficulty - w[6]*float64(grade-recallGradeGood)
+		item.Difficulty = clampDifficulty(w[7]*m.initialDifficulty(recallGradeGood) + (1-w[7])*difficulty)
+
+		item.LastReviewedAt = at
+	}
+
+	item.NumReviews++
+	if !passed {
+		item.NumLapses++
+	}
+}
+
+func clampDifficulty(difficulty float64) float64 {
+	return math.Max(1, math.Min(10, difficulty))
+}
+
+func recallGrade(passed bool) int {
+	if passed {
+		return recallGradeGood
+	}
+	return recallGradeAgain
+}
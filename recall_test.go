@@ -0,0 +1,158 @@
+package wanikaniapi_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	"github.com/brandur/wanikaniapi/wktesting"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewRecallModel(t *testing.T) {
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := func(days float64) time.Time {
+		return start.Add(time.Duration(days * float64(24*time.Hour)))
+	}
+
+	assignments := []*wanikaniapi.Assignment{
+		fixtureAssignment(1, 0, &start, nil, nil),
+		fixtureAssignment(2, 0, &start, nil, nil),
+	}
+	reviews := []*wanikaniapi.Review{
+		// Out of order to show that reviews are replayed by time.
+		fixtureReview(1, 1, 1, false, day(14)),
+		fixtureReview(1, 1, 1, true, day(4)),
+	}
+
+	model, err := wanikaniapi.NewRecallModel(&wanikaniapi.RecallModelParams{
+		Assignments: assignments,
+		Reviews:     reviews,
+	})
+	assert.NoError(t, err)
+
+	t.Run("Lesson", func(t *testing.T) {
+		item := model.Items[2]
+		assert.Equal(t, 3.7145, item.Stability)
+		assert.Equal(t, 5.1618, item.Difficulty)
+		assert.Equal(t, start, item.LastReviewedAt)
+		assert.Equal(t, 0, item.NumReviews)
+
+		// Recall is 90% after one stability by definition.
+		recall, err := model.Predict(2, day(3.7145))
+		assert.NoError(t, err)
+		assert.InDelta(t, 0.9, recall, 1e-9)
+
+		recall, err = model.Predict(2, start)
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, recall)
+	})
+
+	t.Run("Reviews", func(t *testing.T) {
+		// Stability grew after the correct review, then fell after the lapse
+		// and difficulty rose.
+		item := model.Items[1]
+		assert.InDelta(t, 3.0018, item.Stability, 1e-4)
+		assert.InDelta(t, 6.9012, item.Difficulty, 1e-4)
+		assert.Equal(t, day(14), item.LastReviewedAt)
+		assert.Equal(t, 2, item.NumReviews)
+		assert.Equal(t, 1, item.NumLapses)
+
+		_, err := model.Predict(3, start)
+		assert.EqualError(t, err, "no recall history for subject 3")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		incremental, err := wanikaniapi.NewRecallModel(&wanikaniapi.RecallModelParams{
+			Assignments: assignments,
+			Reviews:     reviews[1:],
+		})
+		assert.NoError(t, err)
+		assert.InDelta(t, 14.8081, incremental.Items[1].Stability, 1e-4)
+
+		incremental.Update(reviews[0])
+		assert.Equal(t, model, incremental)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		// The lesson was redone after a reset, so the earlier reviews don't
+		// count.
+		reset, err := wanikaniapi.NewRecallModel(&wanikaniapi.RecallModelParams{
+			Assignments: []*wanikaniapi.Assignment{fixtureAssignment(1, 0, timePtr(day(20)), nil, nil)},
+			Reviews:     reviews,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3.7145, reset.Items[1].Stability)
+		assert.Equal(t, 0, reset.Items[1].NumReviews)
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		again, err := wanikaniapi.NewRecallModel(&wanikaniapi.RecallModelParams{
+			Assignments: assignments,
+			Reviews:     reviews,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, wktesting.MustMarshalJSON(model), wktesting.MustMarshalJSON(again))
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var decoded wanikaniapi.RecallModel
+		err := json.Unmarshal(wktesting.MustMarshalJSON(model), &decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, model, &decoded)
+	})
+
+	t.Run("Weights", func(t *testing.T) {
+		_, err := wanikaniapi.NewRecallModel(&wanikaniapi.RecallModelParams{Weights: []float64{1}})
+		assert.EqualError(t, err, "RecallModelParams.Weights must have 17 weights")
+	})
+}
+
+func TestRecallModelRankUpcoming(t *testing.T) {
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := func(days float64) time.Time {
+		return start.Add(time.Duration(days * float64(24*time.Hour)))
+	}
+
+	model, err := wanikaniapi.NewRecallModel(&wanikaniapi.RecallModelParams{
+		Assignments: []*wanikaniapi.Assignment{
+			fixtureAssignment(1, 0, &start, nil, nil),
+			fixtureAssignment(2, 0, &start, nil, nil),
+			fixtureAssignment(3, 0, &start, nil, nil),
+		},
+		Reviews: []*wanikaniapi.Review{
+			fixtureReview(2, 1, 1, false, day(1)),
+		},
+	})
+	assert.NoError(t, err)
+
+	upcoming := []*wanikaniapi.Assignment{
+		fixtureAssignment(1, 0, &start, timePtr(day(2)), nil),
+		fixtureAssignment(2, 0, &start, timePtr(day(4)), nil),
+		fixtureAssignment(3, 0, &start, timePtr(day(5)), nil),
+
+		// Available after the cutoff.
+		fixtureAssignment(3, 0, &start, timePtr(day(30)), nil),
+
+		// No history.
+		fixtureAssignment(4, 0, &start, timePtr(day(2)), nil),
+	}
+
+	predictions := model.RankUpcoming(upcoming, day(7))
+
+	var subjectIDs []wanikaniapi.WKID
+	for _, prediction := range predictions {
+		subjectIDs = append(subjectIDs, prediction.Item.SubjectID)
+	}
+
+	// The lapsed subject is most at risk, followed by the one that's been
+	// left the longest.
+	assert.Equal(t, []wanikaniapi.WKID{2, 3, 1}, subjectIDs)
+	assert.Equal(t, day(5), predictions[1].At)
+	assert.Equal(t, upcoming[2], predictions[1].Assignment)
+
+	recall, err := model.Predict(3, day(5))
+	assert.NoError(t, err)
+	assert.Equal(t, recall, predictions[1].Recall)
+}
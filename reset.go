@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -58,7 +59,7 @@ type Reset struct {
 type ResetData struct {
 	ConfirmedAt   *time.Time `json:"confirmed_at"`
 	CreatedAt     time.Time  `json:"created_at"`
-	OriginalLevel int        `json:"original_evel"`
+	OriginalLevel int        `json:"original_level"`
 	TargetLevel   int        `json:"target_level"`
 }
 
@@ -96,3 +97,13 @@ type ResetPage struct {
 	PageObject
 	Data []*Reset `json:"data"`
 }
+
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p ResetPage) MarshalJSON() ([]byte, error) {
+	type page ResetPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
@@ -0,0 +1,346 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// CompareRetention compares the retention curves of a pool of users, keyed by
+// a name for each user, against the pool as a whole. All curves must have
+// been estimated with the same confidence.
+func CompareRetention(curves map[string]*RetentionCurve) (*RetentionComparison, error) {
+	comparison := &RetentionComparison{}
+
+	var confidence float64
+	for user, curve := range curves {
+		if confidence != 0 && curve.Confidence != confidence {
+			return nil, fmt.Errorf("retention curves must have the same confidence to be compared")
+		}
+		confidence = curve.Confidence
+
+		comparison.Users = append(comparison.Users, user)
+	}
+	sort.Strings(comparison.Users)
+
+	stages := make(map[int]*RetentionComparisonStage)
+	for _, user := range comparison.Users {
+		for _, stage := range curves[user].Stages {
+			compared, ok := stages[stage.Stage]
+			if !ok {
+				compared = &RetentionComparisonStage{
+					ByUser: make(map[string]*RetentionComparisonUser),
+					Pool:   &RetentionRate{},
+					Stage:  stage.Stage,
+				}
+				stages[stage.Stage] = compared
+				comparison.Stages = append(comparison.Stages, compared)
+			}
+
+			compared.Pool.NumPassed += stage.NumPassed
+			compared.Pool.NumReviews += stage.NumReviews
+			compared.ByUser[user] = &RetentionComparisonUser{Rate: &stage.RetentionRate}
+		}
+	}
+	sort.Slice(comparison.Stages, func(i, j int) bool { return comparison.Stages[i].Stage < comparison.Stages[j].Stage })
+
+	for _, stage := range comparison.Stages {
+		stage.Pool.estimate(confidence)
+
+		for _, user := range stage.ByUser {
+			user.Difference = user.Rate.PassRate - stage.Pool.PassRate
+			user.Significant = stage.Pool.PassRate < user.Rate.Lower || stage.Pool.PassRate > user.Rate.Upper
+		}
+	}
+
+	return comparison, nil
+}
+
+// NewRetentionCurve estimates how well a user retains subjects at each SRS
+// stage from their review history. A review passes if it moved the assignment
+// to a later stage, meaning that it was answered without any incorrect
+// answers.
+//
+// Reviews are grouped by their starting SRS stage. Systems like WaniKani's
+// accelerated one have shorter intervals at early stages, so reviews from
+// different systems are best estimated separately with
+// RetentionCurveParams.SpacedRepetitionSystemIDs.
+func NewRetentionCurve(params *RetentionCurveParams) (*RetentionCurve, error) {
+	confidence := params.Confidence
+	if confidence == 0 {
+		confidence = RetentionDefaultConfidence
+	}
+	if confidence <= 0 || confidence >= 1 {
+		return nil, fmt.Errorf("RetentionCurveParams.Confidence must be between 0 and 1")
+	}
+
+	subjects := make(map[WKID]*Subject, len(params.Subjects))
+	for _, subject := range params.Subjects {
+		subjects[subject.ID] = subject
+	}
+
+	curve := &RetentionCurve{
+		ByType:     make(map[WKObjectType]*RetentionRate),
+		Confidence: confidence,
+	}
+
+	stages := make(map[int]*RetentionStage)
+	for _, review := range params.Reviews {
+		data := review.Data
+		if data == nil {
+			continue
+		}
+
+		if !params.Since.IsZero() && data.CreatedAt.Before(params.Since) {
+			continue
+		}
+		if !params.Until.IsZero() && !data.CreatedAt.Before(params.Until) {
+			continue
+		}
+
+		if params.SpacedRepetitionSystemIDs != nil && !wkidsContain(params.SpacedRepetitionSystemIDs, data.SpacedRepetitionSystemID) {
+			continue
+		}
+
+		subject, ok := subjects[data.SubjectID]
+		if !ok {
+			return nil, fmt.Errorf("subject %v not found", data.SubjectID)
+		}
+
+		level := subject.Level()
+		if params.MinLevel != 0 && level < params.MinLevel {
+			continue
+		}
+		if params.MaxLevel != 0 && level > params.MaxLevel {
+			continue
+		}
+
+		stage, ok := stages[data.StartingSRSStage]
+		if !ok {
+			stage = &RetentionStage{
+				ByType: make(map[WKObjectType]*RetentionRate),
+				Stage:  data.StartingSRSStage,
+			}
+			stages[data.StartingSRSStage] = stage
+			curve.Stages = append(curve.Stages, stage)
+		}
+
+		byType, ok := stage.ByType[subject.Type()]
+		if !ok {
+			byType = &RetentionRate{}
+			stage.ByType[subject.Type()] = byType
+		}
+
+		curveByType, ok := curve.ByType[subject.Type()]
+		if !ok {
+			curveByType = &RetentionRate{}
+			curve.ByType[subject.Type()] = curveByType
+		}
+
+		passed := data.EndingSRSStage > data.StartingSRSStage
+		for _, rate := range []*RetentionRate{&curve.RetentionRate, curveByType, &stage.RetentionRate, byType} {
+			rate.NumReviews++
+			if passed {
+				rate.NumPassed++
+			}
+		}
+	}
+	sort.Slice(curve.Stages, func(i, j int) bool { return curve.Stages[i].Stage < curve.Stages[j].Stage })
+
+	curve.estimate(confidence)
+	for _, rate := range curve.ByType {
+		rate.estimate(confidence)
+	}
+	for _, stage := range curve.Stages {
+		stage.estimate(confidence)
+		for _, rate := range stage.ByType {
+			rate.estimate(confidence)
+		}
+	}
+
+	return curve, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// RetentionDefaultConfidence is the default RetentionCurveParams.Confidence.
+const RetentionDefaultConfidence = 0.95
+
+// RetentionComparison compares the retention curves of a pool of users. See
+// CompareRetention.
+type RetentionComparison struct {
+	// Stages are the compared SRS stages in order, including every stage that
+	// any user has reviews for.
+	Stages []*RetentionComparisonStage
+
+	// Users are the names of the compared users in alphabetical order.
+	Users []string
+}
+
+// RetentionComparisonStage compares users' retention at a single SRS stage.
+type RetentionComparisonStage struct {
+	// ByUser are the users' retention at the stage keyed by their names.
+	// Users without reviews at the stage are missing.
+	ByUser map[string]*RetentionComparisonUser
+
+	// Pool is the retention of all users' reviews at the stage combined.
+	Pool *RetentionRate
+
+	// Stage is the starting SRS stage position of the reviews.
+	Stage int
+}
+
+// RetentionComparisonUser is a user's retention at an SRS stage compared to
+// their pool.
+type RetentionComparisonUser struct {
+	// Difference is the user's pass rate minus the pool's. Negative if the
+	// user retains less than the pool.
+	Difference float64
+
+	// Rate is the user's retention at the stage.
+	Rate *RetentionRate
+
+	// Significant is whether the pool's pass rate is outside of the user's
+	// confidence interval, suggesting that the difference isn't just chance.
+	Significant bool
+}
+
+// RetentionCurve is an estimate of how well a user retains subjects at each
+// SRS stage. The embedded RetentionRate covers every review in the curve. See
+// NewRetentionCurve.
+type RetentionCurve struct {
+	RetentionRate
+
+	// ByType is the retention of every review in the curve by subject type.
+	ByType map[WKObjectType]*RetentionRate
+
+	// Confidence is the confidence level of the curve's intervals.
+	Confidence float64
+
+	// Stages are the SRS stages that have reviews in order.
+	Stages []*RetentionStage
+}
+
+// RetentionCurveParams are parameters for NewRetentionCurve.
+type RetentionCurveParams struct {
+	// Confidence is the confidence level of intervals, between 0 and 1.
+	// Defaults to RetentionDefaultConfidence.
+	Confidence float64
+
+	// MaxLevel only includes reviews of subjects at this level or lower. Zero
+	// for no maximum.
+	MaxLevel int
+
+	// MinLevel only includes reviews of subjects at this level or higher. Zero
+	// for no minimum.
+	MinLevel int
+
+	// Reviews are the user's reviews.
+	Reviews []*Review
+
+	// Since only includes reviews created at or after this time. Zero for no
+	// start.
+	Since time.Time
+
+	// SpacedRepetitionSystemIDs only includes reviews of subjects in these
+	// spaced repetition systems. Nil includes all of them.
+	SpacedRepetitionSystemIDs []WKID
+
+	// Subjects are the subjects of Reviews, which are needed for their types
+	// and levels.
+	Subjects []*Subject
+
+	// Until only includes reviews created before this time. Zero for no end.
+	Until time.Time
+}
+
+// RetentionRate is the fraction of reviews that passed along with a
+// confidence interval for it.
+type RetentionRate struct {
+	// Lower is the lower bound of the pass rate's confidence interval.
+	Lower float64
+
+	// NumPassed is the number of reviews that passed.
+	NumPassed int
+
+	// NumReviews is the number of reviews.
+	NumReviews int
+
+	// PassRate is the fraction of reviews that passed. Zero without reviews.
+	PassRate float64
+
+	// Upper is the upper bound of the pass rate's confidence interval.
+	Upper float64
+}
+
+// RetentionStage is the retention of reviews that started at a single SRS
+// stage. The embedded RetentionRate covers every review at the stage.
+type RetentionStage struct {
+	RetentionRate
+
+	// ByType is the retention at the stage by subject type.
+	ByType map[WKObjectType]*RetentionRate
+
+	// Stage is the starting SRS stage position of the reviews.
+	Stage int
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// estimate fills in the pass rate and its Wilson score interval, which stays
+// within zero and one and behaves well for small numbers of reviews and rates
+// near zero or one.
+func (r *RetentionRate) estimate(confidence float64) {
+	if r.NumReviews == 0 {
+		r.Lower, r.PassRate, r.Upper = 0, 0, 0
+		return
+	}
+
+	n := float64(r.NumReviews)
+	p := float64(r.NumPassed) / n
+	z := math.Sqrt2 * math.Erfinv(confidence)
+
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	halfWidth := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+
+	r.PassRate = p
+	r.Lower = math.Max(0, center-halfWidth)
+	r.Upper = math.Min(1, center+halfWidth)
+}
+
+func wkidsContain(ids []WKID, id WKID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package wanikaniapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewRetentionCurve(t *testing.T) {
+	day := func(day int) time.Time {
+		return time.Date(2021, 3, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	subjects := []*wanikaniapi.Subject{
+		fixtureKanji(1, 1, 1),
+		{
+			Object: wanikaniapi.Object{ID: 2, ObjectType: wanikaniapi.ObjectTypeRadical},
+			RadicalData: &wanikaniapi.SubjectRadicalData{
+				SubjectCommonData: wanikaniapi.SubjectCommonData{Level: 1},
+			},
+		},
+		{
+			Object: wanikaniapi.Object{ID: 3, ObjectType: wanikaniapi.ObjectTypeVocabulary},
+			VocabularyData: &wanikaniapi.SubjectVocabularyData{
+				SubjectCommonData: wanikaniapi.SubjectCommonData{Level: 10},
+			},
+		},
+	}
+
+	reviews := []*wanikaniapi.Review{
+		fixtureReview(1, 1, 1, true, day(1)),
+		fixtureReview(1, 1, 1, true, day(2)),
+		fixtureReview(1, 1, 1, false, day(3)),
+		fixtureReview(2, 1, 1, true, day(4)),
+		fixtureReview(3, 1, 5, false, day(5)),
+		fixtureReview(3, 1, 5, false, day(6)),
+
+		// In the accelerated system.
+		fixtureReview(1, 2, 1, false, day(7)),
+	}
+
+	t.Run("All", func(t *testing.T) {
+		curve, err := wanikaniapi.NewRetentionCurve(&wanikaniapi.RetentionCurveParams{
+			Reviews:  reviews,
+			Subjects: subjects,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, wanikaniapi.RetentionDefaultConfidence, curve.Confidence)
+		assert.Equal(t, 7, curve.NumReviews)
+		assert.Equal(t, 3, curve.NumPassed)
+		assert.Equal(t, 3, len(curve.ByType))
+		assert.Equal(t, 4, curve.ByType[wanikaniapi.ObjectTypeKanji].NumReviews)
+
+		assert.Equal(t, 2, len(curve.Stages))
+
+		stage := curve.Stages[0]
+		assert.Equal(t, 1, stage.Stage)
+		assert.Equal(t, 5, stage.NumReviews)
+		assert.Equal(t, 3, stage.NumPassed)
+		assert.Equal(t, 0.6, stage.PassRate)
+		assert.Equal(t, 2, len(stage.ByType))
+		assert.Equal(t, 1.0, stage.ByType[wanikaniapi.ObjectTypeRadical].PassRate)
+
+		stage = curve.Stages[1]
+		assert.Equal(t, 5, stage.Stage)
+		assert.Equal(t, 0.0, stage.PassRate)
+		assert.Equal(t, 0.0, stage.Lower)
+		assert.InDelta(t, 0.6576, stage.Upper, 1e-4)
+	})
+
+	t.Run("SpacedRepetitionSystemIDs", func(t *testing.T) {
+		curve, err := wanikaniapi.NewRetentionCurve(&wanikaniapi.RetentionCurveParams{
+			Reviews:                   reviews,
+			SpacedRepetitionSystemIDs: []wanikaniapi.WKID{1},
+			Subjects:                  subjects,
+		})
+		assert.NoError(t, err)
+
+		// Wilson score interval of 3 out of 4.
+		stage := curve.Stages[0]
+		assert.Equal(t, 0.75, stage.PassRate)
+		assert.InDelta(t, 0.3006, stage.Lower, 1e-4)
+		assert.InDelta(t, 0.9544, stage.Upper, 1e-4)
+	})
+
+	t.Run("Cohort", func(t *testing.T) {
+		curve, err := wanikaniapi.NewRetentionCurve(&wanikaniapi.RetentionCurveParams{
+			MaxLevel: 5,
+			Reviews:  reviews,
+			Since:    day(2),
+			Subjects: subjects,
+			Until:    day(7),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, curve.NumReviews)
+		assert.Equal(t, 1, len(curve.Stages))
+
+		curve, err = wanikaniapi.NewRetentionCurve(&wanikaniapi.RetentionCurveParams{
+			MinLevel: 5,
+			Reviews:  reviews,
+			Subjects: subjects,
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, curve.NumReviews)
+		assert.Equal(t, 5, curve.Stages[0].Stage)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := wanikaniapi.NewRetentionCurve(&wanikaniapi.RetentionCurveParams{
+			Reviews:  reviews,
+			Subjects: subjects[1:],
+		})
+		assert.EqualError(t, err, "subject 1 not found")
+
+		_, err = wanikaniapi.NewRetentionCurve(&wanikaniapi.RetentionCurveParams{Confidence: 1.5})
+		assert.EqualError(t, err, "RetentionCurveParams.Confidence must be between 0 and 1")
+	})
+}
+
+func TestCompareRetention(t *testing.T) {
+	subjects := []*wanikaniapi.Subject{fixtureKanji(1, 1, 1)}
+
+	curve := func(numPassed, numReviews int) *wanikaniapi.RetentionCurve {
+		var reviews []*wanikaniapi.Review
+		for i := 0; i < numReviews; i++ {
+			reviews = append(reviews, fixtureReview(1, 1, 4, i < numPassed, time.Time{}))
+		}
+
+		curve, err := wanikaniapi.NewRetentionCurve(&wanikaniapi.RetentionCurveParams{
+			Reviews:  reviews,
+			Subjects: subjects,
+		})
+		assert.NoError(t, err)
+		return curve
+	}
+
+	comparison, err := wanikaniapi.CompareRetention(map[string]*wanikaniapi.RetentionCurve{
+		"carol": curve(0, 10),
+		"alice": curve(10, 10),
+		"bob":   curve(6, 10),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"alice", "bob", "carol"}, comparison.Users)
+	assert.Equal(t, 1, len(comparison.Stages))
+
+	stage := comparison.Stages[0]
+	assert.Equal(t, 4, stage.Stage)
+	assert.Equal(t, 30, stage.Pool.NumReviews)
+	assert.Equal(t, 16, stage.Pool.NumPassed)
+
+	// Bob's interval includes the pool's pass rate, but Alice's and Carol's
+	// don't.
+	assert.InDelta(t, 1-16.0/30, stage.ByUser["alice"].Difference, 1e-9)
+	assert.True(t, stage.ByUser["alice"].Significant)
+	assert.InDelta(t, 0.6-16.0/30, stage.ByUser["bob"].Difference, 1e-9)
+	assert.False(t, stage.ByUser["bob"].Significant)
+	assert.True(t, stage.ByUser["carol"].Significant)
+
+	mismatched := curve(1, 1)
+	mismatched.Confidence = 0.9
+	_, err = wanikaniapi.CompareRetention(map[string]*wanikaniapi.RetentionCurve{
+		"alice": curve(1, 1),
+		"bob":   mismatched,
+	})
+	assert.EqualError(t, err, "retention curves must have the same confidence to be compared")
+}
@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -46,6 +47,29 @@ func (c *Client) ReviewList(params *ReviewListParams) (*ReviewPage, error) {
 	return obj, err
 }
 
+// ReviewListStream is like ReviewList, except that instead of decoding the
+// whole page into memory at once, reviews are decoded one at a time and passed
+// to onReview as they're read off the wire. Returning an error from onReview
+// stops decoding and the error is returned.
+//
+// The returned PageObject contains pagination information and can be returned
+// from a function passed to PageFully.
+//
+// Requests are retried as usual, but only until the first review has been
+// passed to onReview.
+func (c *Client) ReviewListStream(params *ReviewListParams, onReview func(*Review) error) (*PageObject, error) {
+	streamer := &pageStreamer{
+		newItem: func() interface{} {
+			return &Review{}
+		},
+		onItem: func(item interface{}) error {
+			return onReview(item.(*Review))
+		},
+	}
+	err := c.request("GET", "/v2/reviews", params, nil, streamer)
+	return &streamer.PageObject, err
+}
+
 //////////////////////////////////////////////////////////////////////////////
 //
 //
@@ -64,6 +88,11 @@ func (c *Client) ReviewList(params *ReviewListParams) (*ReviewPage, error) {
 type Review struct {
 	Object
 	Data *ReviewData `json:"data"`
+
+	// ResourcesUpdated contains the assignment and review statistic that were
+	// updated as a result of creating a review. Only populated on the
+	// response to ReviewCreate.
+	ResourcesUpdated *ReviewResourcesUpdated `json:"resources_updated,omitempty"`
 }
 
 // ReviewCreateParams are parameters for ReviewCreate.
@@ -93,6 +122,13 @@ type ReviewData struct {
 	SubjectID                WKID      `json:"subject_id"`
 }
 
+// ReviewResourcesUpdated contains resources updated as a side effect of
+// creating a review.
+type ReviewResourcesUpdated struct {
+	Assignment      *Assignment      `json:"assignment"`
+	ReviewStatistic *ReviewStatistic `json:"review_statistic"`
+}
+
 // ReviewGetParams are parameters for ReviewGet.
 type ReviewGetParams struct {
 	Params
@@ -138,3 +174,13 @@ type ReviewPage struct {
 	PageObject
 	Data []*Review `json:"data"`
 }
+
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p ReviewPage) MarshalJSON() ([]byte, error) {
+	type page ReviewPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
@@ -0,0 +1,206 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewReviewQueue orders a user's available reviews with a ReviewQueueStrategy.
+//
+// Reviews are shuffled with ReviewQueueParams.Rand before being ordered by the
+// strategy, so reviews that the strategy considers equal come in random order
+// like they do on WaniKani. Use a seeded *rand.Rand for a repeatable order.
+//
+// With ReviewQueueParams.WrapUp, only that many reviews are queued and the rest
+// are deferred, like WaniKani's wrap-up mode.
+func NewReviewQueue(params *ReviewQueueParams) (*ReviewQueue, error) {
+	if params.Now.IsZero() {
+		return nil, fmt.Errorf("ReviewQueueParams.Now must be set")
+	}
+	if params.WrapUp < 0 {
+		return nil, fmt.Errorf("ReviewQueueParams.WrapUp must be positive")
+	}
+
+	strategy := params.Strategy
+	if strategy == "" {
+		strategy = ReviewQueueStrategyRandom
+	}
+
+	var less func(a, b *ReviewQueueItem) bool
+	switch strategy {
+	case ReviewQueueStrategyCurrentLevelFirst:
+		if params.CurrentLevel == 0 {
+			return nil, fmt.Errorf("ReviewQueueParams.CurrentLevel must be set for %q", strategy)
+		}
+		less = func(a, b *ReviewQueueItem) bool {
+			return a.Subject.Level() == params.CurrentLevel && b.Subject.Level() != params.CurrentLevel
+		}
+
+	case ReviewQueueStrategyLowestStageFirst:
+		less = func(a, b *ReviewQueueItem) bool {
+			return a.Assignment.Data.SRSStage < b.Assignment.Data.SRSStage
+		}
+
+	case ReviewQueueStrategyOldestAvailableFirst:
+		less = func(a, b *ReviewQueueItem) bool {
+			return a.Assignment.Data.AvailableAt.Before(*b.Assignment.Data.AvailableAt)
+		}
+
+	case ReviewQueueStrategyRandom:
+		// Shuffled only.
+
+	case ReviewQueueStrategyType:
+		less = func(a, b *ReviewQueueItem) bool {
+			return subjectTypeRank(a.Subject) < subjectTypeRank(b.Subject)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown review queue strategy %q", strategy)
+	}
+
+	rnd := params.Rand
+	if rnd == nil {
+		rnd = globalRand{}
+	}
+
+	subjects := make(map[WKID]*Subject, len(params.Subjects))
+	for _, subject := range params.Subjects {
+		subjects[subject.ID] = subject
+	}
+
+	var items []*ReviewQueueItem
+	for _, assignment := range params.Assignments {
+		data := assignment.Data
+		if data == nil || data.Hidden || data.StartedAt == nil || data.BurnedAt != nil ||
+			data.AvailableAt == nil || data.AvailableAt.After(params.Now) {
+			continue
+		}
+
+		subject, ok := subjects[data.SubjectID]
+		if !ok {
+			return nil, fmt.Errorf("subject %v not found", data.SubjectID)
+		}
+
+		items = append(items, &ReviewQueueItem{Assignment: assignment, Subject: subject})
+	}
+
+	// Start from a stable order so that a shuffle with the same seed always
+	// gives the same result regardless of the order of Assignments.
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Subject.ID < items[j].Subject.ID
+	})
+
+	shuffle(rnd, len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+
+	if less != nil {
+		sort.SliceStable(items, func(i, j int) bool {
+			return less(items[i], items[j])
+		})
+	}
+
+	queue := &ReviewQueue{Items: items}
+	if params.WrapUp > 0 && params.WrapUp < len(items) {
+		queue.Deferred = items[params.WrapUp:]
+		queue.Items = items[:params.WrapUp]
+	}
+
+	return queue, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// ReviewQueueWrapUpSize is the number of reviews that WaniKani's wrap-up mode
+// leaves in a session, for use with ReviewQueueParams.WrapUp.
+const ReviewQueueWrapUpSize = 10
+
+// ReviewQueueStrategy is how reviews are ordered in a ReviewQueue.
+type ReviewQueueStrategy string
+
+// All possible values of ReviewQueueStrategy.
+const (
+	// ReviewQueueStrategyCurrentLevelFirst puts reviews of subjects of
+	// ReviewQueueParams.CurrentLevel first.
+	ReviewQueueStrategyCurrentLevelFirst ReviewQueueStrategy = "current_level_first"
+
+	// ReviewQueueStrategyLowestStageFirst orders reviews by SRS stage,
+	// lowest first.
+	ReviewQueueStrategyLowestStageFirst ReviewQueueStrategy = "lowest_stage_first"
+
+	// ReviewQueueStrategyOldestAvailableFirst orders reviews by when they
+	// became available, oldest first.
+	ReviewQueueStrategyOldestAvailableFirst ReviewQueueStrategy = "oldest_available_first"
+
+	// ReviewQueueStrategyRandom shuffles reviews.
+	ReviewQueueStrategyRandom ReviewQueueStrategy = "random"
+
+	// ReviewQueueStrategyType orders reviews by subject type: radicals, kanji,
+	// then vocabulary.
+	ReviewQueueStrategyType ReviewQueueStrategy = "type"
+)
+
+// ReviewQueue is a user's available reviews in order. See NewReviewQueue.
+type ReviewQueue struct {
+	// Deferred are available reviews that were left out of Items by
+	// ReviewQueueParams.WrapUp, in order.
+	Deferred []*ReviewQueueItem
+
+	// Items are the reviews to do in order.
+	Items []*ReviewQueueItem
+}
+
+// ReviewQueueItem is a review in a ReviewQueue.
+type ReviewQueueItem struct {
+	// Assignment is the review's assignment.
+	Assignment *Assignment
+
+	// Subject is the review's subject.
+	Subject *Subject
+}
+
+// ReviewQueueParams are parameters for NewReviewQueue.
+type ReviewQueueParams struct {
+	// Assignments are the user's assignments. Those that aren't available for
+	// review at Now are skipped.
+	Assignments []*Assignment
+
+	// CurrentLevel is the user's level. Required for
+	// ReviewQueueStrategyCurrentLevelFirst.
+	CurrentLevel int
+
+	// Now is the current time, which determines which reviews are available.
+	Now time.Time
+
+	// Rand is the source of randomness for shuffles. Defaults to the global
+	// source in math/rand. Use a seeded *rand.Rand for a repeatable order.
+	Rand Rand
+
+	// Strategy is how reviews are ordered. Defaults to
+	// ReviewQueueStrategyRandom.
+	Strategy ReviewQueueStrategy
+
+	// Subjects are the subjects of Assignments.
+	Subjects []*Subject
+
+	// WrapUp is the most reviews to queue, like WaniKani's wrap-up mode. See
+	// ReviewQueueWrapUpSize. Zero for no limit.
+	WrapUp int
+}
@@ -0,0 +1,191 @@
+package wanikaniapi_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewReviewQueue(t *testing.T) {
+	now := time.Date(2021, 3, 14, 12, 0, 0, 0, time.UTC)
+	hour := func(hour, min int) time.Time {
+		return time.Date(2021, 3, 14, hour, min, 0, 0, time.UTC)
+	}
+
+	subject := func(id wanikaniapi.WKID, objectType wanikaniapi.WKObjectType, level int) *wanikaniapi.Subject {
+		return fixtureSubject(id, objectType, wanikaniapi.SubjectCommonData{Level: level})
+	}
+
+	subjects := []*wanikaniapi.Subject{
+		subject(1, wanikaniapi.ObjectTypeRadical, 5),
+		subject(2, wanikaniapi.ObjectTypeKanji, 5),
+		subject(3, wanikaniapi.ObjectTypeVocabulary, 4),
+		subject(4, wanikaniapi.ObjectTypeKanji, 4),
+		subject(5, wanikaniapi.ObjectTypeRadical, 4),
+		subject(6, wanikaniapi.ObjectTypeKanji, 5),
+		subject(7, wanikaniapi.ObjectTypeKanji, 3),
+		subject(8, wanikaniapi.ObjectTypeKanji, 5),
+	}
+
+	review := func(subjectID wanikaniapi.WKID, stage int, availableAt time.Time) *wanikaniapi.Assignment {
+		return fixtureAssignment(subjectID, stage, timePtr(hour(0, 0)), &availableAt, nil)
+	}
+
+	assignments := []*wanikaniapi.Assignment{
+		review(1, 1, hour(11, 0)),
+		review(2, 3, hour(9, 0)),
+		review(3, 2, hour(10, 0)),
+		review(4, 5, hour(8, 0)),
+		review(5, 4, hour(11, 30)),
+
+		// Not available yet.
+		review(6, 1, hour(13, 0)),
+
+		// Burned.
+		{Data: &wanikaniapi.AssignmentData{
+			BurnedAt: timePtr(hour(0, 0)), SRSStage: 9, StartedAt: timePtr(hour(0, 0)), SubjectID: 7,
+		}},
+
+		// A lesson.
+		{Data: &wanikaniapi.AssignmentData{SubjectID: 8, UnlockedAt: timePtr(hour(0, 0))}},
+	}
+
+	queueSubjectIDs := func(items []*wanikaniapi.ReviewQueueItem) []wanikaniapi.WKID {
+		var ids []wanikaniapi.WKID
+		for _, item := range items {
+			ids = append(ids, item.Subject.ID)
+		}
+		return ids
+	}
+
+	newQueue := func(t *testing.T, params wanikaniapi.ReviewQueueParams) *wanikaniapi.ReviewQueue {
+		if params.Assignments == nil {
+			params.Assignments = assignments
+		}
+		params.Now = now
+		params.Rand = rand.New(rand.NewSource(1))
+		params.Subjects = subjects
+
+		queue, err := wanikaniapi.NewReviewQueue(&params)
+		assert.NoError(t, err)
+		return queue
+	}
+
+	t.Run("Random", func(t *testing.T) {
+		ids := queueSubjectIDs(newQueue(t, wanikaniapi.ReviewQueueParams{}).Items)
+		assert.ElementsMatch(t, []wanikaniapi.WKID{1, 2, 3, 4, 5}, ids)
+
+		// The same seed gives the same order, even if assignments come in a
+		// different order.
+		reversed := make([]*wanikaniapi.Assignment, len(assignments))
+		copy(reversed, assignments)
+		sort.Slice(reversed, func(i, j int) bool { return reversed[i].Data.SubjectID > reversed[j].Data.SubjectID })
+		assert.Equal(t, ids, queueSubjectIDs(newQueue(t, wanikaniapi.ReviewQueueParams{
+			Assignments: reversed,
+			Strategy:    wanikaniapi.ReviewQueueStrategyRandom,
+		}).Items))
+
+		// Some seed shuffles differently.
+		var different bool
+		for seed := int64(2); seed < 10; seed++ {
+			queue, err := wanikaniapi.NewReviewQueue(&wanikaniapi.ReviewQueueParams{
+				Assignments: assignments,
+				Now:         now,
+				Rand:        rand.New(rand.NewSource(seed)),
+				Subjects:    subjects,
+			})
+			assert.NoError(t, err)
+			if !reflect.DeepEqual(ids, queueSubjectIDs(queue.Items)) {
+				different = true
+				break
+			}
+		}
+		assert.True(t, different)
+	})
+
+	t.Run("LowestStageFirst", func(t *testing.T) {
+		queue := newQueue(t, wanikaniapi.ReviewQueueParams{
+			Strategy: wanikaniapi.ReviewQueueStrategyLowestStageFirst,
+		})
+		assert.Equal(t, []wanikaniapi.WKID{1, 3, 2, 5, 4}, queueSubjectIDs(queue.Items))
+		assert.Empty(t, queue.Deferred)
+	})
+
+	t.Run("OldestAvailableFirst", func(t *testing.T) {
+		queue := newQueue(t, wanikaniapi.ReviewQueueParams{
+			Strategy: wanikaniapi.ReviewQueueStrategyOldestAvailableFirst,
+		})
+		assert.Equal(t, []wanikaniapi.WKID{4, 2, 3, 1, 5}, queueSubjectIDs(queue.Items))
+	})
+
+	t.Run("CurrentLevelFirst", func(t *testing.T) {
+		ids := queueSubjectIDs(newQueue(t, wanikaniapi.ReviewQueueParams{
+			CurrentLevel: 5,
+			Strategy:     wanikaniapi.ReviewQueueStrategyCurrentLevelFirst,
+		}).Items)
+		assert.ElementsMatch(t, []wanikaniapi.WKID{1, 2}, ids[:2])
+		assert.ElementsMatch(t, []wanikaniapi.WKID{3, 4, 5}, ids[2:])
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		ids := queueSubjectIDs(newQueue(t, wanikaniapi.ReviewQueueParams{
+			Strategy: wanikaniapi.ReviewQueueStrategyType,
+		}).Items)
+		assert.ElementsMatch(t, []wanikaniapi.WKID{1, 5}, ids[:2])
+		assert.ElementsMatch(t, []wanikaniapi.WKID{2, 4}, ids[2:4])
+		assert.Equal(t, []wanikaniapi.WKID{3}, ids[4:])
+	})
+
+	t.Run("WrapUp", func(t *testing.T) {
+		queue := newQueue(t, wanikaniapi.ReviewQueueParams{
+			Strategy: wanikaniapi.ReviewQueueStrategyLowestStageFirst,
+			WrapUp:   2,
+		})
+		assert.Equal(t, []wanikaniapi.WKID{1, 3}, queueSubjectIDs(queue.Items))
+		assert.Equal(t, []wanikaniapi.WKID{2, 5, 4}, queueSubjectIDs(queue.Deferred))
+
+		// More than are available.
+		queue = newQueue(t, wanikaniapi.ReviewQueueParams{
+			Strategy: wanikaniapi.ReviewQueueStrategyLowestStageFirst,
+			WrapUp:   wanikaniapi.ReviewQueueWrapUpSize,
+		})
+		assert.Equal(t, 5, len(queue.Items))
+		assert.Empty(t, queue.Deferred)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := wanikaniapi.NewReviewQueue(&wanikaniapi.ReviewQueueParams{
+			Assignments: assignments,
+			Now:         now,
+			Strategy:    "alphabetical",
+			Subjects:    subjects,
+		})
+		assert.EqualError(t, err, `unknown review queue strategy "alphabetical"`)
+
+		_, err = wanikaniapi.NewReviewQueue(&wanikaniapi.ReviewQueueParams{
+			Assignments: assignments,
+			Now:         now,
+			Strategy:    wanikaniapi.ReviewQueueStrategyCurrentLevelFirst,
+			Subjects:    subjects,
+		})
+		assert.EqualError(t, err, `ReviewQueueParams.CurrentLevel must be set for "current_level_first"`)
+
+		_, err = wanikaniapi.NewReviewQueue(&wanikaniapi.ReviewQueueParams{
+			Assignments: assignments,
+			Now:         now,
+			Subjects:    subjects[1:],
+		})
+		assert.EqualError(t, err, "subject 1 not found")
+
+		_, err = wanikaniapi.NewReviewQueue(&wanikaniapi.ReviewQueueParams{
+			Assignments: assignments,
+			Subjects:    subjects,
+		})
+		assert.EqualError(t, err, "ReviewQueueParams.Now must be set")
+	})
+}
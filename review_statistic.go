@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -129,3 +130,13 @@ type ReviewStatisticPage struct {
 	PageObject
 	Data []*ReviewStatistic `json:"data"`
 }
+
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p ReviewStatisticPage) MarshalJSON() ([]byte, error) {
+	type page ReviewStatisticPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
@@ -53,3 +53,40 @@ func TestReviewGet(t *testing.T) {
 	assert.Equal(t, "/v2/reviews/123", req.Path)
 	assert.Equal(t, "", req.Query)
 }
+
+func TestReviewListStream(t *testing.T) {
+	client := wktesting.LocalClient()
+
+	client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {"per_page": 1000, "next_url": null, "previous_url": null},
+			"total_count": 2,
+			"data": [
+				{"id": 1, "object": "review", "data": {"subject_id": 123, "ending_srs_stage": 2}},
+				{"id": 2, "object": "review", "data": {"subject_id": 124, "ending_srs_stage": 3}}
+			]
+		}`)},
+	}
+
+	var reviews []*wanikaniapi.Review
+	page, err := client.ReviewListStream(&wanikaniapi.ReviewListParams{
+		SubjectIDs: []wanikaniapi.WKID{123, 124},
+	}, func(review *wanikaniapi.Review) error {
+		reviews = append(reviews, review)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(2), page.TotalCount)
+	assert.Equal(t, "", page.Pages.NextURL)
+
+	assert.Equal(t, 2, len(reviews))
+	assert.Equal(t, wanikaniapi.WKID(123), reviews[0].Data.SubjectID)
+	assert.Equal(t, 3, reviews[1].Data.EndingSRSStage)
+
+	req := client.RecordedRequests[0]
+	assert.Equal(t, http.MethodGet, req.Method)
+	assert.Equal(t, "/v2/reviews", req.Path)
+	assert.Equal(t, "subject_ids=123,124", wktesting.MustQueryUnescape(req.Query))
+}
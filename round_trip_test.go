@@ -0,0 +1,383 @@
+package wanikaniapi_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+// roundTripResources are all the resource types that should survive a trip
+// through JSON marshaling and unmarshaling unchanged.
+var roundTripResources = []interface{}{
+	&wanikaniapi.Assignment{},
+	&wanikaniapi.LevelProgression{},
+	&wanikaniapi.Reset{},
+	&wanikaniapi.Review{},
+	&wanikaniapi.ReviewStatistic{},
+	&wanikaniapi.SpacedRepetitionSystem{},
+	&wanikaniapi.StudyMaterial{},
+	&wanikaniapi.Subject{},
+	&wanikaniapi.Summary{},
+	&wanikaniapi.User{},
+	&wanikaniapi.VoiceActor{},
+}
+
+// Property test: randomly generated resources are equal to themselves after
+// being marshaled and unmarshaled again.
+func TestRoundTripProperty(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, resource := range roundTripResources {
+		typ := reflect.TypeOf(resource).Elem()
+
+		t.Run(typ.Name(), func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				original := randomResource(r, typ)
+
+				marshaled, err := json.Marshal(original)
+				assert.NoError(t, err)
+
+				decoded := reflect.New(typ).Interface()
+				assert.NoError(t, json.Unmarshal(marshaled, decoded))
+				assert.Equal(t, original, exportedFields(decoded), "marshaled: %s", marshaled)
+
+				// And marshaling a second time is stable.
+				remarshaled, err := json.Marshal(decoded)
+				assert.NoError(t, err)
+				assert.Equal(t, string(marshaled), string(remarshaled))
+			}
+		})
+	}
+}
+
+// Fixtures taken from WaniKani's API marshal back to the same JSON that they
+// were decoded from.
+func TestRoundTripFixtures(t *testing.T) {
+	fixtures := map[string]interface{}{
+		"assignment.json":                           &wanikaniapi.Assignment{},
+		"level_progression.json":                    &wanikaniapi.LevelProgression{},
+		"reset.json":                                &wanikaniapi.Reset{},
+		"review.json":                               &wanikaniapi.Review{},
+		"review_create.json":                        &wanikaniapi.Review{},
+		"review_statistic.json":                     &wanikaniapi.ReviewStatistic{},
+		"spaced_repetition_system.json":             &wanikaniapi.SpacedRepetitionSystem{},
+		"spaced_repetition_system_accelerated.json": &wanikaniapi.SpacedRepetitionSystem{},
+		"study_material.json":                       &wanikaniapi.StudyMaterial{},
+		"subject_kana_vocabulary.json":              &wanikaniapi.Subject{},
+		"subject_kanji.json":                        &wanikaniapi.Subject{},
+		"subject_radical.json":                      &wanikaniapi.Subject{},
+		"subject_vocabulary.json":                   &wanikaniapi.Subject{},
+		"summary.json":                              &wanikaniapi.Summary{},
+		"user.json":                                 &wanikaniapi.User{},
+		"voice_actor.json":                          &wanikaniapi.VoiceActor{},
+	}
+
+	for fixture, resource := range fixtures {
+		resource := resource
+
+		t.Run(fixture, func(t *testing.T) {
+			original, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+			assert.NoError(t, err)
+
+			assert.NoError(t, json.Unmarshal(original, resource))
+
+			marshaled, err := json.Marshal(resource)
+			assert.NoError(t, err)
+
+			assert.Equal(t, normalizeJSON(t, original), normalizeJSON(t, marshaled))
+		})
+	}
+}
+
+func TestMarshalJSONZeroID(t *testing.T) {
+	// WaniKani sends the user, the summary, and collections without an `id`.
+	for _, resource := range []interface{}{
+		&wanikaniapi.AssignmentPage{},
+		&wanikaniapi.LevelProgressionPage{},
+		&wanikaniapi.ResetPage{},
+		&wanikaniapi.ReviewPage{},
+		&wanikaniapi.ReviewStatisticPage{},
+		&wanikaniapi.SpacedRepetitionSystemPage{},
+		&wanikaniapi.StudyMaterialPage{},
+		&wanikaniapi.SubjectPage{},
+		&wanikaniapi.Summary{},
+		&wanikaniapi.SummaryPage{},
+		&wanikaniapi.User{},
+		&wanikaniapi.VoiceActorPage{},
+	} {
+		t.Run(reflect.TypeOf(resource).Elem().Name(), func(t *testing.T) {
+			marshaled, err := json.Marshal(resource)
+			assert.NoError(t, err)
+
+			var objMap map[string]json.RawMessage
+			assert.NoError(t, json.Unmarshal(marshaled, &objMap))
+			assert.NotContains(t, objMap, "id")
+			assert.Contains(t, objMap, "object")
+
+			// An ID is still included if it's set.
+			reflect.ValueOf(resource).Elem().FieldByName("ID").SetInt(123)
+			marshaled, err = json.Marshal(resource)
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(marshaled, &objMap))
+			assert.Equal(t, "123", string(objMap["id"]))
+		})
+	}
+
+	// Collections keep their data.
+	page := &wanikaniapi.SubjectPage{Data: []*wanikaniapi.Subject{{Object: wanikaniapi.Object{ID: 440}}}}
+	marshaled, err := json.Marshal(page)
+	assert.NoError(t, err)
+	assert.Contains(t, string(marshaled), `"data":[{`)
+	assert.Contains(t, string(marshaled), `"id":440`)
+}
+
+func TestSubjectMarshalJSON(t *testing.T) {
+	subject := wanikaniapi.Subject{
+		Object: wanikaniapi.Object{ID: 440, ObjectType: wanikaniapi.ObjectTypeKanji},
+		KanjiData: &wanikaniapi.SubjectKanjiData{
+			Characters: "一",
+		},
+	}
+
+	marshaled, err := json.Marshal(subject)
+	assert.NoError(t, err)
+
+	var objMap map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(marshaled, &objMap))
+	assert.Equal(t, `"kanji"`, string(objMap["object"]))
+	assert.Contains(t, string(objMap["data"]), `"characters":"一"`)
+	assert.NotContains(t, objMap, "KanjiData")
+
+	// Also works through a pointer.
+	marshaledPtr, err := json.Marshal(&subject)
+	assert.NoError(t, err)
+	assert.Equal(t, string(marshaled), string(marshaledPtr))
+}
+
+func TestWKTimeUnmarshalJSON(t *testing.T) {
+	goT := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var wkT wanikaniapi.WKTime
+	assert.NoError(t, json.Unmarshal([]byte(`"2021-01-02T03:04:05Z"`), &wkT))
+	assert.Equal(t, goT, time.Time(wkT))
+
+	marshaled, err := json.Marshal(wkT)
+	assert.NoError(t, err)
+	assert.Equal(t, `"2021-01-02T03:04:05Z"`, string(marshaled))
+
+	assert.NoError(t, json.Unmarshal([]byte(`null`), &wkT))
+	assert.Equal(t, goT, time.Time(wkT))
+
+	assert.Error(t, json.Unmarshal([]byte(`"yesterday"`), &wkT))
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+var (
+	pronounciationAudioReflectType   = reflect.TypeOf(wanikaniapi.SubjectVocabularyPronounciationAudio{})
+	radicalCharacterImageReflectType = reflect.TypeOf(wanikaniapi.SubjectRadicalCharacterImage{})
+	subjectReflectType               = reflect.TypeOf(wanikaniapi.Subject{})
+	timeReflectType                  = reflect.TypeOf(time.Time{})
+)
+
+// normalizeJSON decodes JSON into generic values, rewriting timestamps into
+// a canonical form because WaniKani always includes microseconds while Go
+// drops trailing zeros.
+// exportedFields returns a copy of a decoded resource with only its exported
+// fields so that it can be compared to one that was built directly. Subjects
+// of unknown types also hold data decoded from UnknownData.
+func exportedFields(resource interface{}) interface{} {
+	subject, ok := resource.(*wanikaniapi.Subject)
+	if !ok {
+		return resource
+	}
+
+	return &wanikaniapi.Subject{
+		Object:             subject.Object,
+		KanaVocabularyData: subject.KanaVocabularyData,
+		KanjiData:          subject.KanjiData,
+		RadicalData:        subject.RadicalData,
+		UnknownData:        subject.UnknownData,
+		VocabularyData:     subject.VocabularyData,
+	}
+}
+
+func normalizeJSON(t *testing.T, data []byte) interface{} {
+	var v interface{}
+	assert.NoError(t, json.Unmarshal(data, &v))
+	return normalizeJSONValue(v)
+}
+
+func normalizeJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, elem := range val {
+			val[key] = normalizeJSONValue(elem)
+		}
+	case []interface{}:
+		for i, elem := range val {
+			val[i] = normalizeJSONValue(elem)
+		}
+	case string:
+		if parsed, err := time.Parse(time.RFC3339Nano, val); err == nil {
+			return parsed.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return v
+}
+
+// randomResource generates a pointer to a random value of the given resource
+// type.
+func randomResource(r *rand.Rand, typ reflect.Type) interface{} {
+	v := reflect.New(typ)
+	fillRandom(r, v.Elem(), "")
+	return v.Interface()
+}
+
+// fillRandom fills a value with random data, only populating fields that
+// appear in JSON. Subjects are special cased so that only one of their data
+// fields is set, consistent with their object type.
+func fillRandom(r *rand.Rand, v reflect.Value, tag string) {
+	switch {
+	case v.Type() == timeReflectType:
+		// WaniKani's timestamps have microsecond precision.
+		v.Set(reflect.ValueOf(time.Unix(r.Int63n(2e9), r.Int63n(1e6)*1e3).UTC()))
+		return
+
+	case v.Type() == subjectReflectType:
+		subject := v.Addr().Interface().(*wanikaniapi.Subject)
+		fillRandom(r, reflect.ValueOf(&subject.Object).Elem(), "")
+		switch r.Intn(5) {
+		case 0:
+			subject.ObjectType = wanikaniapi.ObjectTypeKanji
+			subject.KanjiData = &wanikaniapi.SubjectKanjiData{}
+			fillRandom(r, reflect.ValueOf(subject.KanjiData).Elem(), "")
+		case 1:
+			subject.ObjectType = wanikaniapi.ObjectTypeRadical
+			subject.RadicalData = &wanikaniapi.SubjectRadicalData{}
+			fillRandom(r, reflect.ValueOf(subject.RadicalData).Elem(), "")
+		case 2:
+			subject.ObjectType = wanikaniapi.ObjectTypeVocabulary
+			subject.VocabularyData = &wanikaniapi.SubjectVocabularyData{}
+			fillRandom(r, reflect.ValueOf(subject.VocabularyData).Elem(), "")
+		case 3:
+			subject.ObjectType = wanikaniapi.ObjectTypeKanaVocabulary
+			subject.KanaVocabularyData = &wanikaniapi.SubjectKanaVocabularyData{}
+			fillRandom(r, reflect.ValueOf(subject.KanaVocabularyData).Elem(), "")
+		case 4:
+			subject.ObjectType = wanikaniapi.WKObjectType("future_type")
+			data, err := json.Marshal(map[string]string{"characters": randomString(r)})
+			if err != nil {
+				panic(err)
+			}
+			subject.UnknownData = data
+		}
+		return
+
+	case v.Type() == radicalCharacterImageReflectType:
+		image := v.Addr().Interface().(*wanikaniapi.SubjectRadicalCharacterImage)
+		image.ContentType = randomString(r)
+		image.URL = randomString(r)
+		if r.Intn(4) != 0 {
+			image.Metadata = map[wanikaniapi.SubjectRadicalCharacterImageMetadataKey]interface{}{
+				wanikaniapi.SubjectRadicalCharacterImageMetadataKeyDimensions:   randomString(r),
+				wanikaniapi.SubjectRadicalCharacterImageMetadataKeyInlineStyles: r.Intn(2) == 0,
+			}
+			image.TypedMetadata = &wanikaniapi.SubjectRadicalCharacterImageMetadata{
+				Dimensions:   image.Metadata[wanikaniapi.SubjectRadicalCharacterImageMetadataKeyDimensions].(string),
+				InlineStyles: wanikaniapi.Bool(image.Metadata[wanikaniapi.SubjectRadicalCharacterImageMetadataKeyInlineStyles].(bool)),
+			}
+		}
+		return
+
+	case v.Type() == pronounciationAudioReflectType:
+		audio := v.Addr().Interface().(*wanikaniapi.SubjectVocabularyPronounciationAudio)
+		audio.ContentType = randomString(r)
+		audio.URL = randomString(r)
+		if r.Intn(4) != 0 {
+			audio.TypedMetadata = &wanikaniapi.SubjectVocabularyPronounciationAudioMetadata{
+				Gender:       randomString(r),
+				VoiceActorID: wanikaniapi.WKID(r.Intn(10)),
+			}
+			audio.Metadata = map[wanikaniapi.SubjectVocabularyPronounciationAudioMetadataKey]interface{}{
+				wanikaniapi.SubjectVocabularyPronounciationAudioMetadataKeyGender:       audio.TypedMetadata.Gender,
+				wanikaniapi.SubjectVocabularyPronounciationAudioMetadataKeyVoiceActorID: float64(audio.TypedMetadata.VoiceActorID),
+			}
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 0)
+
+	case reflect.Int, reflect.Int64:
+		v.SetInt(r.Int63n(100000))
+
+	case reflect.String:
+		v.SetString(randomString(r))
+
+	case reflect.Ptr:
+		// Fields tagged `omitempty` are omitted when nil, which decodes back
+		// to nil, so both cases round trip.
+		if r.Intn(3) == 0 {
+			return
+		}
+		ptr := reflect.New(v.Type().Elem())
+		fillRandom(r, ptr.Elem(), "")
+		v.Set(ptr)
+
+	case reflect.Slice:
+		if r.Intn(4) == 0 {
+			return
+		}
+		slice := reflect.MakeSlice(v.Type(), r.Intn(3), r.Intn(3)+3)
+		for i := 0; i < slice.Len(); i++ {
+			fillRandom(r, slice.Index(i), "")
+		}
+		v.Set(slice)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			fieldTag := field.Tag.Get("json")
+			if fieldTag == "-" || field.PkgPath != "" {
+				continue
+			}
+			fillRandom(r, v.Field(i), fieldTag)
+		}
+
+	default:
+		panic(fmt.Sprintf("don't know how to generate %v (tag: %q)", v.Type(), tag))
+	}
+}
+
+// randomString generates a random string that includes some characters that
+// need escaping in JSON.
+func randomString(r *rand.Rand) string {
+	const alphabet = "abcxyz一二三あいう<>&\"\\ "
+	runes := []rune(alphabet)
+
+	var sb strings.Builder
+	for i := r.Intn(8); i > 0; i-- {
+		sb.WriteRune(runes[r.Intn(len(runes))])
+	}
+	return sb.String()
+}
@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -61,6 +62,17 @@ type SpacedRepetitionSystemData struct {
 	UnlockingStagePosition int                                   `json:"unlocking_stage_position"`
 }
 
+// Stage returns the stage at the given position, or nil if the system has no
+// such stage.
+func (d *SpacedRepetitionSystemData) Stage(position int) *SpacedRepetitionSystemStagedObject {
+	for _, stage := range d.Stages {
+		if stage.Position == position {
+			return stage
+		}
+	}
+	return nil
+}
+
 // SpacedRepetitionSystemStagedObject represents an spaced repetition stage.
 type SpacedRepetitionSystemStagedObject struct {
 	Interval     *int    `json:"interval"`
@@ -102,3 +114,13 @@ type SpacedRepetitionSystemPage struct {
 	PageObject
 	Data []*SpacedRepetitionSystem `json:"data"`
 }
+
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p SpacedRepetitionSystemPage) MarshalJSON() ([]byte, error) {
+	type page SpacedRepetitionSystemPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
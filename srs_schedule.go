@@ -0,0 +1,148 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// All possible values of SpacedRepetitionSystemStagedObject.IntervalUnit.
+const (
+	SRSIntervalUnitDays         = "days"
+	SRSIntervalUnitHours        = "hours"
+	SRSIntervalUnitMilliseconds = "milliseconds"
+	SRSIntervalUnitMinutes      = "minutes"
+	SRSIntervalUnitSeconds      = "seconds"
+	SRSIntervalUnitWeeks        = "weeks"
+)
+
+// SRSSchedule is the predicted result of a lesson or review: the SRS stage
+// that an assignment moves to and when it next becomes available for review.
+type SRSSchedule struct {
+	// AvailableAt is when the assignment next becomes available for review.
+	// Nil if it moved to a stage without an interval, like the burning stage.
+	AvailableAt *time.Time
+
+	// Stage is the position of the SRS stage that the assignment moved to.
+	Stage int
+}
+
+// IntervalDuration returns the stage's interval as a duration, converting it
+// from its IntervalUnit. Returns zero for stages without an interval like the
+// unlocking and burning stages, and an error if the unit isn't known.
+func (s *SpacedRepetitionSystemStagedObject) IntervalDuration() (time.Duration, error) {
+	if s.Interval == nil {
+		return 0, nil
+	}
+
+	var unit string
+	if s.IntervalUnit != nil {
+		unit = *s.IntervalUnit
+	}
+
+	perUnit, ok := srsIntervalUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown SRS interval unit %q", unit)
+	}
+
+	return time.Duration(*s.Interval) * perUnit, nil
+}
+
+// NextStage returns the SRS stage that an assignment at the given stage moves
+// to after a review with numIncorrect incorrect answers, counting incorrect
+// meaning and reading answers together.
+//
+// A review without incorrect answers moves up one stage. Otherwise, WaniKani's
+// penalty formula moves it down by the number of incorrect answers divided by
+// two and rounded up, doubled for stages at or beyond the passing stage, but
+// never below the starting stage.
+func (d *SpacedRepetitionSystemData) NextStage(stage, numIncorrect int) int {
+	if numIncorrect <= 0 {
+		return stage + 1
+	}
+
+	adjustment := (numIncorrect + 1) / 2
+
+	penaltyFactor := 1
+	if stage >= d.PassingStagePosition {
+		penaltyFactor = 2
+	}
+
+	next := stage - adjustment*penaltyFactor
+	if next < d.StartingStagePosition {
+		next = d.StartingStagePosition
+	}
+
+	return next
+}
+
+// ScheduleLesson predicts the stage and availability of an assignment whose
+// lesson was completed at startedAt.
+func (d *SpacedRepetitionSystemData) ScheduleLesson(startedAt time.Time) (*SRSSchedule, error) {
+	return d.schedule(d.StartingStagePosition, startedAt)
+}
+
+// ScheduleReview predicts the stage and availability of an assignment at the
+// given stage after a review at reviewedAt with numIncorrect incorrect
+// answers. See NextStage for how the stage is chosen.
+//
+// As in WaniKani, AvailableAt is the stage's interval after reviewedAt,
+// floored to the hour. Returns an error if the stage can't be reviewed, like
+// the unlocking or burning stage.
+func (d *SpacedRepetitionSystemData) ScheduleReview(stage, numIncorrect int, reviewedAt time.Time) (*SRSSchedule, error) {
+	if stage < d.StartingStagePosition || stage >= d.BurningStagePosition || d.Stage(stage) == nil {
+		return nil, fmt.Errorf("SRS stage %v of spaced repetition system %q can't be reviewed", stage, d.Name)
+	}
+
+	return d.schedule(d.NextStage(stage, numIncorrect), reviewedAt)
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// srsIntervalUnits maps SRS interval units to their length.
+var srsIntervalUnits = map[string]time.Duration{
+	SRSIntervalUnitDays:         24 * time.Hour,
+	SRSIntervalUnitHours:        time.Hour,
+	SRSIntervalUnitMilliseconds: time.Millisecond,
+	SRSIntervalUnitMinutes:      time.Minute,
+	SRSIntervalUnitSeconds:      time.Second,
+	SRSIntervalUnitWeeks:        7 * 24 * time.Hour,
+}
+
+func (d *SpacedRepetitionSystemData) schedule(stage int, at time.Time) (*SRSSchedule, error) {
+	stageObj := d.Stage(stage)
+	if stageObj == nil {
+		return nil, fmt.Errorf("SRS stage %v isn't a stage of spaced repetition system %q", stage, d.Name)
+	}
+
+	schedule := &SRSSchedule{Stage: stage}
+
+	if stageObj.Interval != nil {
+		interval, err := stageObj.IntervalDuration()
+		if err != nil {
+			return nil, err
+		}
+
+		availableAt := at.Add(interval).Truncate(time.Hour)
+		schedule.AvailableAt = &availableAt
+	}
+
+	return schedule, nil
+}
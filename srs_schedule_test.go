@@ -0,0 +1,144 @@
+package wanikaniapi_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSpacedRepetitionSystemStagedObjectIntervalDuration(t *testing.T) {
+	testCases := []struct {
+		interval *int
+		unit     *string
+		expected time.Duration
+	}{
+		{nil, nil, 0},
+		{wanikaniapi.Int(1500), wanikaniapi.String(wanikaniapi.SRSIntervalUnitMilliseconds), 1500 * time.Millisecond},
+		{wanikaniapi.Int(14400), wanikaniapi.String(wanikaniapi.SRSIntervalUnitSeconds), 4 * time.Hour},
+		{wanikaniapi.Int(90), wanikaniapi.String(wanikaniapi.SRSIntervalUnitMinutes), 90 * time.Minute},
+		{wanikaniapi.Int(23), wanikaniapi.String(wanikaniapi.SRSIntervalUnitHours), 23 * time.Hour},
+		{wanikaniapi.Int(7), wanikaniapi.String(wanikaniapi.SRSIntervalUnitDays), 7 * 24 * time.Hour},
+		{wanikaniapi.Int(2), wanikaniapi.String(wanikaniapi.SRSIntervalUnitWeeks), 14 * 24 * time.Hour},
+	}
+
+	for _, tc := range testCases {
+		stage := &wanikaniapi.SpacedRepetitionSystemStagedObject{Interval: tc.interval, IntervalUnit: tc.unit}
+		duration, err := stage.IntervalDuration()
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, duration)
+	}
+
+	stage := &wanikaniapi.SpacedRepetitionSystemStagedObject{
+		Interval:     wanikaniapi.Int(1),
+		IntervalUnit: wanikaniapi.String("fortnights"),
+	}
+	_, err := stage.IntervalDuration()
+	assert.EqualError(t, err, `unknown SRS interval unit "fortnights"`)
+}
+
+func TestSpacedRepetitionSystemDataNextStage(t *testing.T) {
+	// Next stages by current stage (rows, 1 through 8) and number of
+	// incorrect answers (columns, 0 through 6). Both of WaniKani's systems
+	// share stage positions, so they share this table.
+	expected := [][]int{
+		{2, 1, 1, 1, 1, 1, 1},
+		{3, 1, 1, 1, 1, 1, 1},
+		{4, 2, 2, 1, 1, 1, 1},
+		{5, 3, 3, 2, 2, 1, 1},
+		{6, 3, 3, 1, 1, 1, 1}, // penalty doubles from the passing stage
+		{7, 4, 4, 2, 2, 1, 1},
+		{8, 5, 5, 3, 3, 1, 1},
+		{9, 6, 6, 4, 4, 2, 2},
+	}
+
+	for _, fixture := range []string{"spaced_repetition_system.json", "spaced_repetition_system_accelerated.json"} {
+		srs := mustLoadSRS(t, fixture)
+
+		for i, row := range expected {
+			stage := i + 1
+			for numIncorrect, next := range row {
+				assert.Equal(t, next, srs.Data.NextStage(stage, numIncorrect),
+					"%s: stage %v with %v incorrect", srs.Data.Name, stage, numIncorrect)
+			}
+		}
+	}
+}
+
+func TestSpacedRepetitionSystemDataScheduleReview(t *testing.T) {
+	// Intervals in hours of each stage from 1 through 8.
+	intervals := map[string][]int{
+		"spaced_repetition_system.json":             {4, 8, 23, 47, 167, 335, 719, 2879},
+		"spaced_repetition_system_accelerated.json": {2, 4, 8, 23, 167, 335, 719, 2879},
+	}
+
+	reviewedAt := time.Date(2021, 3, 14, 10, 35, 20, 0, time.UTC)
+	reviewedHour := time.Date(2021, 3, 14, 10, 0, 0, 0, time.UTC)
+
+	for fixture, hours := range intervals {
+		srs := mustLoadSRS(t, fixture)
+
+		t.Run(srs.Data.Name, func(t *testing.T) {
+			for stage := 1; stage <= 8; stage++ {
+				for numIncorrect := 0; numIncorrect <= 8; numIncorrect++ {
+					name := fmt.Sprintf("stage %v with %v incorrect", stage, numIncorrect)
+
+					schedule, err := srs.Data.ScheduleReview(stage, numIncorrect, reviewedAt)
+					assert.NoError(t, err, name)
+					assert.Equal(t, srs.Data.NextStage(stage, numIncorrect), schedule.Stage, name)
+
+					if schedule.Stage == srs.Data.BurningStagePosition {
+						assert.Nil(t, schedule.AvailableAt, name)
+						continue
+					}
+
+					// Floored to the hour, so the reviewed minutes and
+					// seconds are dropped.
+					expected := reviewedHour.Add(time.Duration(hours[schedule.Stage-1]) * time.Hour)
+					assert.Equal(t, expected, *schedule.AvailableAt, name)
+				}
+			}
+
+			for _, stage := range []int{0, 9, 10, -1} {
+				_, err := srs.Data.ScheduleReview(stage, 0, reviewedAt)
+				assert.EqualError(t, err,
+					fmt.Sprintf("SRS stage %v of spaced repetition system %q can't be reviewed", stage, srs.Data.Name))
+			}
+		})
+	}
+
+	t.Run("FlooredToHour", func(t *testing.T) {
+		srs := mustLoadSRS(t, "spaced_repetition_system_accelerated.json")
+
+		// A review just before the hour still floors to the start of the
+		// hour, so the assignment is available almost an hour early.
+		schedule, err := srs.Data.ScheduleReview(1, 0, time.Date(2021, 3, 14, 10, 59, 59, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2021, 3, 14, 14, 0, 0, 0, time.UTC), *schedule.AvailableAt)
+
+		// Flooring is to the hour in absolute time, so it's unaffected by
+		// time zones with offsets that aren't whole hours.
+		india := time.FixedZone("IST", 5*60*60+30*60)
+		schedule, err = srs.Data.ScheduleReview(1, 0, time.Date(2021, 3, 14, 16, 40, 0, 0, india))
+		assert.NoError(t, err)
+		assert.True(t, time.Date(2021, 3, 14, 15, 0, 0, 0, time.UTC).Equal(*schedule.AvailableAt))
+	})
+}
+
+func TestSpacedRepetitionSystemDataScheduleLesson(t *testing.T) {
+	startedAt := time.Date(2021, 3, 14, 23, 15, 0, 0, time.UTC)
+
+	srs := mustLoadSRS(t, "spaced_repetition_system.json")
+	schedule, err := srs.Data.ScheduleLesson(startedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, schedule.Stage)
+	assert.Equal(t, time.Date(2021, 3, 15, 3, 0, 0, 0, time.UTC), *schedule.AvailableAt)
+
+	srs = mustLoadSRS(t, "spaced_repetition_system_accelerated.json")
+	schedule, err = srs.Data.ScheduleLesson(startedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, schedule.Stage)
+	assert.Equal(t, time.Date(2021, 3, 15, 1, 0, 0, 0, time.UTC), *schedule.AvailableAt)
+}
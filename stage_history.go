@@ -0,0 +1,263 @@
+package wanikaniapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported functions
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// NewStageHistory reconstructs the SRS stage of each of a user's subjects over
+// time by replaying their history: assignments are unlocked at UnlockedAt,
+// move to the starting stage at StartedAt, and move to each review's
+// EndingSRSStage as it's created.
+//
+// A confirmed reset locks every subject at or above its TargetLevel again.
+// Because assignments only keep their latest unlock and start times, a subject
+// that was reset only reappears before its current UnlockedAt at its first
+// review, and isn't counted between being unlocked and reviewed the first
+// time before the reset.
+func NewStageHistory(params *StageHistoryParams) (*StageHistory, error) {
+	systems := newSubjectSystems(params.Subjects, params.SpacedRepetitionSystems)
+
+	subjects := make(map[WKID]*Subject, len(params.Subjects))
+	for _, subject := range params.Subjects {
+		subjects[subject.ID] = subject
+	}
+
+	var events []*stageHistoryEvent
+	for _, assignment := range params.Assignments {
+		data := assignment.Data
+		if data == nil {
+			continue
+		}
+		if data.UnlockedAt != nil {
+			events = append(events, &stageHistoryEvent{
+				at: *data.UnlockedAt, kind: stageHistoryEventUnlock, subjectID: data.SubjectID,
+			})
+		}
+		if data.StartedAt != nil {
+			events = append(events, &stageHistoryEvent{
+				at: *data.StartedAt, kind: stageHistoryEventStart, subjectID: data.SubjectID,
+			})
+		}
+	}
+	for _, review := range params.Reviews {
+		data := review.Data
+		if data == nil {
+			continue
+		}
+		events = append(events, &stageHistoryEvent{
+			at: data.CreatedAt, kind: stageHistoryEventReview, stage: data.EndingSRSStage, subjectID: data.SubjectID,
+		})
+	}
+	for _, reset := range params.Resets {
+		data := reset.Data
+		if data == nil || data.ConfirmedAt == nil {
+			continue
+		}
+		events = append(events, &stageHistoryEvent{
+			at: *data.ConfirmedAt, kind: stageHistoryEventReset, targetLevel: data.TargetLevel,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].kind < events[j].kind
+	})
+
+	history := &StageHistory{subjects: make(map[WKID][]*stageHistoryTransition)}
+
+	for _, event := range events {
+		if event.kind == stageHistoryEventReset {
+			for subjectID, transitions := range history.subjects {
+				last := transitions[len(transitions)-1]
+				if last.locked || subjects[subjectID].Level() < event.targetLevel {
+					continue
+				}
+				history.subjects[subjectID] = append(transitions, &stageHistoryTransition{at: event.at, locked: true})
+			}
+			continue
+		}
+
+		if _, ok := subjects[event.subjectID]; !ok {
+			return nil, fmt.Errorf("subject %v not found", event.subjectID)
+		}
+
+		srs, err := systems.forSubject(event.subjectID)
+		if err != nil {
+			return nil, err
+		}
+
+		stage := event.stage
+		switch event.kind {
+		case stageHistoryEventUnlock:
+			stage = srs.Data.UnlockingStagePosition
+		case stageHistoryEventStart:
+			stage = srs.Data.StartingStagePosition
+		}
+
+		state, err := srs.Data.StageState(stage)
+		if err != nil {
+			return nil, err
+		}
+
+		history.subjects[event.subjectID] = append(history.subjects[event.subjectID],
+			&stageHistoryTransition{at: event.at, group: state.Group, stage: stage})
+	}
+
+	return history, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Exported constants/types
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// StageDistribution is the number of subjects at each SRS stage at a point in
+// time. Locked subjects aren't counted.
+type StageDistribution struct {
+	// ByGroup is the number of subjects in each stage group like
+	// AssignmentStageGroupGuru.
+	ByGroup map[AssignmentStageGroup]int
+
+	// ByStage is the number of subjects at each SRS stage position.
+	ByStage map[int]int
+
+	// Time is the point in time.
+	Time time.Time
+}
+
+// StageHistory is the reconstructed SRS stage history of a user's subjects.
+// See NewStageHistory.
+type StageHistory struct {
+	// subjects are each subject's transitions in order.
+	subjects map[WKID][]*stageHistoryTransition
+}
+
+// DistributionAt returns the number of subjects at each SRS stage at the given
+// time.
+func (h *StageHistory) DistributionAt(at time.Time) *StageDistribution {
+	distribution := &StageDistribution{
+		ByGroup: make(map[AssignmentStageGroup]int),
+		ByStage: make(map[int]int),
+		Time:    at,
+	}
+
+	for _, transitions := range h.subjects {
+		transition := stageHistoryTransitionAt(transitions, at)
+		if transition == nil || transition.locked {
+			continue
+		}
+
+		distribution.ByGroup[transition.group]++
+		distribution.ByStage[transition.stage]++
+	}
+
+	return distribution
+}
+
+// Series returns the distribution of subjects at each step from start up to
+// and including end, for charting stages over time.
+func (h *StageHistory) Series(start, end time.Time, step time.Duration) ([]*StageDistribution, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+
+	var series []*StageDistribution
+	for at := start; !at.After(end); at = at.Add(step) {
+		series = append(series, h.DistributionAt(at))
+	}
+
+	return series, nil
+}
+
+// StageAt returns the SRS stage position of a subject at the given time.
+// Returns false if the subject was locked.
+func (h *StageHistory) StageAt(subjectID WKID, at time.Time) (int, bool) {
+	transition := stageHistoryTransitionAt(h.subjects[subjectID], at)
+	if transition == nil || transition.locked {
+		return 0, false
+	}
+	return transition.stage, true
+}
+
+// StageHistoryParams are parameters for NewStageHistory.
+type StageHistoryParams struct {
+	// Assignments are the user's assignments, which are needed for when they
+	// were unlocked and started.
+	Assignments []*Assignment
+
+	// Resets are the user's resets.
+	Resets []*Reset
+
+	// Reviews are the user's reviews.
+	Reviews []*Review
+
+	// SpacedRepetitionSystems are the spaced repetition systems of Subjects.
+	SpacedRepetitionSystems []*SpacedRepetitionSystem
+
+	// Subjects are the subjects of Assignments and Reviews, which are needed
+	// for their levels and spaced repetition systems.
+	Subjects []*Subject
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// Kinds of events in a stage history, in the order that they're applied when
+// they happen at the same time.
+const (
+	stageHistoryEventReset = iota
+	stageHistoryEventUnlock
+	stageHistoryEventStart
+	stageHistoryEventReview
+)
+
+type stageHistoryEvent struct {
+	at          time.Time
+	kind        int
+	stage       int
+	subjectID   WKID
+	targetLevel int
+}
+
+type stageHistoryTransition struct {
+	at     time.Time
+	group  AssignmentStageGroup
+	locked bool
+	stage  int
+}
+
+// stageHistoryTransitionAt returns the last transition at or before the given
+// time, or nil if there isn't one.
+func stageHistoryTransitionAt(transitions []*stageHistoryTransition, at time.Time) *stageHistoryTransition {
+	i := sort.Search(len(transitions), func(i int) bool { return transitions[i].at.After(at) })
+	if i == 0 {
+		return nil
+	}
+	return transitions[i-1]
+}
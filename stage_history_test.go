@@ -0,0 +1,147 @@
+package wanikaniapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brandur/wanikaniapi"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewStageHistory(t *testing.T) {
+	srs := mustLoadSRS(t, "spaced_repetition_system.json")
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2021, 3, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	levelTwo := fixtureKanji(2, srs.ID, 1)
+	levelTwo.KanjiData.Level = 2
+
+	subjects := []*wanikaniapi.Subject{
+		fixtureKanji(1, srs.ID, 1),
+		levelTwo,
+		fixtureKanji(3, srs.ID, 1),
+	}
+
+	review := func(subjectID wanikaniapi.WKID, endingStage int, createdAt time.Time) *wanikaniapi.Review {
+		return &wanikaniapi.Review{Data: &wanikaniapi.ReviewData{
+			CreatedAt:      createdAt,
+			EndingSRSStage: endingStage,
+			SubjectID:      subjectID,
+		}}
+	}
+
+	params := &wanikaniapi.StageHistoryParams{
+		Assignments: []*wanikaniapi.Assignment{
+			{Data: &wanikaniapi.AssignmentData{
+				StartedAt: timePtr(at(1, 12)), SubjectID: 1, UnlockedAt: timePtr(at(1, 0)),
+			}},
+
+			// Unlocked and started again after the reset.
+			{Data: &wanikaniapi.AssignmentData{
+				StartedAt: timePtr(at(8, 0)), SubjectID: 2, UnlockedAt: timePtr(at(7, 0)),
+			}},
+
+			// Unlocked, but its lesson was never done.
+			{Data: &wanikaniapi.AssignmentData{SubjectID: 3, UnlockedAt: timePtr(at(1, 0))}},
+		},
+		Resets: []*wanikaniapi.Reset{
+			{Data: &wanikaniapi.ResetData{ConfirmedAt: timePtr(at(5, 0)), OriginalLevel: 2, TargetLevel: 2}},
+
+			// Never confirmed.
+			{Data: &wanikaniapi.ResetData{OriginalLevel: 2, TargetLevel: 1}},
+		},
+		Reviews: []*wanikaniapi.Review{
+			review(1, 2, at(2, 0)),
+			review(1, 3, at(3, 0)),
+			review(1, 4, at(4, 0)),
+			review(1, 5, at(6, 0)),
+
+			// Before the reset.
+			review(2, 5, at(3, 0)),
+			review(2, 6, at(4, 12)),
+		},
+		SpacedRepetitionSystems: []*wanikaniapi.SpacedRepetitionSystem{srs},
+		Subjects:                subjects,
+	}
+
+	history, err := wanikaniapi.NewStageHistory(params)
+	assert.NoError(t, err)
+
+	t.Run("StageAt", func(t *testing.T) {
+		testCases := []struct {
+			subjectID wanikaniapi.WKID
+			at        time.Time
+			stage     int
+			unlocked  bool
+		}{
+			{1, at(1, 0).Add(-time.Second), 0, false},
+			{1, at(1, 0), 0, true},
+			{1, at(1, 12), 1, true},
+			{1, at(3, 12), 3, true},
+			{1, at(9, 0), 5, true},
+			{2, at(1, 0), 0, false},
+			{2, at(3, 0), 5, true},
+			{2, at(4, 12), 6, true},
+			{2, at(5, 0), 0, false}, // reset
+			{2, at(7, 0), 0, true},
+			{2, at(8, 0), 1, true},
+			{3, at(9, 0), 0, true},
+			{4, at(9, 0), 0, false},
+		}
+
+		for _, tc := range testCases {
+			stage, unlocked := history.StageAt(tc.subjectID, tc.at)
+			assert.Equal(t, tc.stage, stage, "subject %v at %v", tc.subjectID, tc.at)
+			assert.Equal(t, tc.unlocked, unlocked, "subject %v at %v", tc.subjectID, tc.at)
+		}
+	})
+
+	t.Run("DistributionAt", func(t *testing.T) {
+		distribution := history.DistributionAt(at(4, 12))
+		assert.Equal(t, at(4, 12), distribution.Time)
+		assert.Equal(t, map[int]int{0: 1, 4: 1, 6: 1}, distribution.ByStage)
+		assert.Equal(t, map[wanikaniapi.AssignmentStageGroup]int{
+			wanikaniapi.AssignmentStageGroupApprentice: 1,
+			wanikaniapi.AssignmentStageGroupGuru:       1,
+			wanikaniapi.AssignmentStageGroupLesson:     1,
+		}, distribution.ByGroup)
+
+		// After the reset, the level 2 subject is locked again.
+		distribution = history.DistributionAt(at(6, 0))
+		assert.Equal(t, map[wanikaniapi.AssignmentStageGroup]int{
+			wanikaniapi.AssignmentStageGroupGuru:   1,
+			wanikaniapi.AssignmentStageGroupLesson: 1,
+		}, distribution.ByGroup)
+	})
+
+	t.Run("Series", func(t *testing.T) {
+		series, err := history.Series(at(1, 0), at(3, 0), 24*time.Hour)
+		assert.NoError(t, err)
+
+		var apprentice, guru []int
+		for _, distribution := range series {
+			apprentice = append(apprentice, distribution.ByGroup[wanikaniapi.AssignmentStageGroupApprentice])
+			guru = append(guru, distribution.ByGroup[wanikaniapi.AssignmentStageGroupGuru])
+		}
+		assert.Equal(t, []int{0, 1, 1}, apprentice)
+		assert.Equal(t, []int{0, 0, 1}, guru)
+		assert.Equal(t, at(3, 0), series[2].Time)
+
+		_, err = history.Series(at(1, 0), at(3, 0), 0)
+		assert.EqualError(t, err, "step must be positive")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		p := *params
+		p.Subjects = subjects[1:]
+		_, err := wanikaniapi.NewStageHistory(&p)
+		assert.EqualError(t, err, "subject 1 not found")
+
+		p = *params
+		p.SpacedRepetitionSystems = nil
+		_, err = wanikaniapi.NewStageHistory(&p)
+		assert.EqualError(t, err, "spaced repetition system 1 of subject 1 not found")
+	})
+}
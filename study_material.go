@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -142,6 +143,16 @@ type StudyMaterialPage struct {
 	Data []*StudyMaterial `json:"data"`
 }
 
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p StudyMaterialPage) MarshalJSON() ([]byte, error) {
+	type page StudyMaterialPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
+
 // StudyMaterialUpdateParams are parameters for StudyMaterialUpdate.
 type StudyMaterialUpdateParams struct {
 	Params
@@ -17,6 +17,55 @@ import (
 //
 //////////////////////////////////////////////////////////////////////////////
 
+// BestPronounciationAudio returns the audio from audios that best matches the
+// given voice actor and content types.
+//
+// Content types are preferred in the order given, like ContentTypeAudioWebM
+// then ContentTypeAudioMPEG, and audio by the voice actor with voiceActorID is
+// preferred over audio by others. Because a player usually can't play a
+// content type it didn't ask for, a matching content type takes precedence
+// over a matching voice actor, so candidates are tried as follows:
+//
+//  1. The voice actor's audio in a requested content type.
+//  2. Any voice actor's audio in a requested content type.
+//  3. The voice actor's audio in any content type.
+//  4. The first audio.
+//
+// A voiceActorID of zero matches any voice actor. Returns nil if audios is
+// empty.
+func BestPronounciationAudio(audios []*SubjectVocabularyPronounciationAudio, voiceActorID WKID, contentTypes ...string) *SubjectVocabularyPronounciationAudio {
+	var byVoiceActor []*SubjectVocabularyPronounciationAudio
+	if voiceActorID == 0 {
+		byVoiceActor = audios
+	} else {
+		for _, audio := range audios {
+			if audio.TypedMetadata != nil && audio.TypedMetadata.VoiceActorID == voiceActorID {
+				byVoiceActor = append(byVoiceActor, audio)
+			}
+		}
+	}
+
+	for _, candidates := range [][]*SubjectVocabularyPronounciationAudio{byVoiceActor, audios} {
+		for _, contentType := range contentTypes {
+			for _, audio := range candidates {
+				if audio.ContentType == contentType {
+					return audio
+				}
+			}
+		}
+	}
+
+	if len(byVoiceActor) > 0 {
+		return byVoiceActor[0]
+	}
+
+	if len(audios) > 0 {
+		return audios[0]
+	}
+
+	return nil
+}
+
 // SubjectGet retrieves a specific subject by its ID. The structure of the
 // response depends on the subject type.
 func (c *Client) SubjectGet(params *SubjectGetParams) (*Subject, error) {
@@ -33,6 +82,29 @@ func (c *Client) SubjectList(params *SubjectListParams) (*SubjectPage, error) {
 	return obj, err
 }
 
+// SubjectListStream is like SubjectList, except that instead of decoding the
+// whole page into memory at once, subjects are decoded one at a time and
+// passed to onSubject as they're read off the wire. Returning an error from
+// onSubject stops decoding and the error is returned.
+//
+// The returned PageObject contains pagination information and can be returned
+// from a function passed to PageFully.
+//
+// Requests are retried as usual, but only until the first subject has been
+// passed to onSubject.
+func (c *Client) SubjectListStream(params *SubjectListParams, onSubject func(*Subject) error) (*PageObject, error) {
+	streamer := &pageStreamer{
+		newItem: func() interface{} {
+			return &Subject{}
+		},
+		onItem: func(item interface{}) error {
+			return onSubject(item.(*Subject))
+		},
+	}
+	err := c.request("GET", "/v2/subjects", params, nil, streamer)
+	return &streamer.PageObject, err
+}
+
 //////////////////////////////////////////////////////////////////////////////
 //
 //
@@ -50,6 +122,10 @@ func (c *Client) SubjectList(params *SubjectListParams) (*SubjectPage, error) {
 type Subject struct {
 	Object
 
+	// KanaVocabularyData is data on a kana vocabulary subject. Populated only
+	// if the subject is a kana vocabulary.
+	KanaVocabularyData *SubjectKanaVocabularyData
+
 	// KanjiData is data on a kanji subject. Populated only if he subject is a
 	// kanji.
 	KanjiData *SubjectKanjiData
@@ -58,9 +134,230 @@ type Subject struct {
 	// is a radical.
 	RadicalData *SubjectRadicalData
 
+	// UnknownData is the raw `data` of a subject whose type isn't known to
+	// this library, like one added to WaniKani after this version was
+	// released. Populated only if the subject's type is unknown.
+	UnknownData json.RawMessage
+
 	// VocabularyData is data on a vocabulary subject. Populated only if he
 	// subject is a vocabulary.
 	VocabularyData *SubjectVocabularyData
+
+	// unknownCommon is common data decoded from UnknownData when the subject
+	// is unmarshaled so that it's not decoded again on every call to Common.
+	unknownCommon *subjectUnknownCommonData
+}
+
+// AcceptedMeanings returns all meanings that are accepted as answers for the
+// subject, including whitelisted auxiliary meanings.
+func (s *Subject) AcceptedMeanings() []string {
+	common := s.Common()
+	if common == nil {
+		return nil
+	}
+
+	var meanings []string
+	for _, meaning := range common.Meanings {
+		if meaning.AcceptedAnswer {
+			meanings = append(meanings, meaning.Meaning)
+		}
+	}
+
+	for _, auxiliaryMeaning := range common.AuxiliaryMeanings {
+		if auxiliaryMeaning.Type == SubjectAuxiliaryMeaningObjectTypeWhitelist {
+			meanings = append(meanings, auxiliaryMeaning.Meaning)
+		}
+	}
+
+	return meanings
+}
+
+// AcceptedReadings returns all readings that are accepted as answers for the
+// subject. Radicals have no readings. Kana vocabulary is read as it's written,
+// so its only reading is its characters.
+func (s *Subject) AcceptedReadings() []string {
+	var readings []string
+
+	switch {
+	case s.KanaVocabularyData != nil:
+		readings = append(readings, s.KanaVocabularyData.Characters)
+
+	case s.KanjiData != nil:
+		for _, reading := range s.KanjiData.Readings {
+			if reading.AcceptedAnswer {
+				readings = append(readings, reading.Reading)
+			}
+		}
+
+	case s.VocabularyData != nil:
+		for _, reading := range s.VocabularyData.Readings {
+			if reading.AcceptedAnswer {
+				readings = append(readings, reading.Reading)
+			}
+		}
+	}
+
+	return readings
+}
+
+// Characters returns the characters of the subject. Returns an empty string
+// for radicals that can only be represented by an image (see
+// SubjectRadicalData.CharacterImages).
+func (s *Subject) Characters() string {
+	switch {
+	case s.KanaVocabularyData != nil:
+		return s.KanaVocabularyData.Characters
+
+	case s.KanjiData != nil:
+		return s.KanjiData.Characters
+
+	case s.RadicalData != nil:
+		if s.RadicalData.Characters != nil {
+			return *s.RadicalData.Characters
+		}
+
+	case s.UnknownData != nil:
+		if data := s.unknownCommonData(); data != nil && data.Characters != nil {
+			return *data.Characters
+		}
+
+	case s.VocabularyData != nil:
+		return s.VocabularyData.Characters
+	}
+
+	return ""
+}
+
+// Common returns data common to all subject types regardless of the type of
+// the subject. For subjects of a type unknown to the library, common data is
+// decoded from UnknownData once when the subject is unmarshaled. Returns nil
+// if the subject has no data.
+func (s *Subject) Common() *SubjectCommonData {
+	switch {
+	case s.KanaVocabularyData != nil:
+		return &s.KanaVocabularyData.SubjectCommonData
+
+	case s.KanjiData != nil:
+		return &s.KanjiData.SubjectCommonData
+
+	case s.RadicalData != nil:
+		return &s.RadicalData.SubjectCommonData
+
+	case s.UnknownData != nil:
+		if data := s.unknownCommonData(); data != nil {
+			return &data.SubjectCommonData
+		}
+		return nil
+
+	case s.VocabularyData != nil:
+		return &s.VocabularyData.SubjectCommonData
+	}
+
+	return nil
+}
+
+// Level returns the level of the subject, or zero if the subject has no data.
+func (s *Subject) Level() int {
+	common := s.Common()
+	if common == nil {
+		return 0
+	}
+
+	return common.Level
+}
+
+// MarshalJSON is a custom JSON marshaling function for Subject. It's the
+// inverse of UnmarshalJSON, producing a `data` field from whichever of
+// KanaVocabularyData, KanjiData, RadicalData, VocabularyData, or UnknownData
+// is set so that the result is in the same shape as WaniKani's API.
+func (s Subject) MarshalJSON() ([]byte, error) {
+	var data interface{}
+	switch {
+	case s.KanaVocabularyData != nil:
+		data = s.KanaVocabularyData
+	case s.KanjiData != nil:
+		data = s.KanjiData
+	case s.RadicalData != nil:
+		data = s.RadicalData
+	case s.UnknownData != nil:
+		data = s.UnknownData
+	case s.VocabularyData != nil:
+		data = s.VocabularyData
+	}
+
+	return json.Marshal(&subjectWire{Object: s.Object, Data: data})
+}
+
+// PrimaryMeaning returns the primary meaning of the subject, or an empty
+// string if it has none.
+func (s *Subject) PrimaryMeaning() string {
+	common := s.Common()
+	if common == nil {
+		return ""
+	}
+
+	for _, meaning := range common.Meanings {
+		if meaning.Primary {
+			return meaning.Meaning
+		}
+	}
+
+	return ""
+}
+
+// PrimaryReading returns the primary reading of the subject, or an empty
+// string if it has none, as is the case for radicals. Kana vocabulary is read
+// as it's written, so its primary reading is its characters.
+func (s *Subject) PrimaryReading() string {
+	switch {
+	case s.KanaVocabularyData != nil:
+		return s.KanaVocabularyData.Characters
+
+	case s.KanjiData != nil:
+		for _, reading := range s.KanjiData.Readings {
+			if reading.Primary {
+				return reading.Reading
+			}
+		}
+
+	case s.VocabularyData != nil:
+		for _, reading := range s.VocabularyData.Readings {
+			if reading.Primary {
+				return reading.Reading
+			}
+		}
+	}
+
+	return ""
+}
+
+// PronounciationAudioForUser returns the subject's pronounciation audio that
+// best matches the user's default voice actor and the given content types,
+// falling back as described in BestPronounciationAudio. Returns nil for
+// subjects that don't have audio, like radicals and kanji.
+func (s *Subject) PronounciationAudioForUser(user *User, contentTypes ...string) *SubjectVocabularyPronounciationAudio {
+	var audios []*SubjectVocabularyPronounciationAudio
+	switch {
+	case s.KanaVocabularyData != nil:
+		audios = s.KanaVocabularyData.PronounciationAudios
+	case s.VocabularyData != nil:
+		audios = s.VocabularyData.PronounciationAudios
+	default:
+		return nil
+	}
+
+	var voiceActorID WKID
+	if user != nil && user.Data != nil && user.Data.Preferences != nil {
+		voiceActorID = user.Data.Preferences.DefaultVoiceActorID
+	}
+
+	return BestPronounciationAudio(audios, voiceActorID, contentTypes...)
+}
+
+// Type returns the type of the subject like ObjectTypeKanji or
+// ObjectTypeRadical.
+func (s *Subject) Type() WKObjectType {
+	return s.ObjectType
 }
 
 // UnmarshalJSON is a custom JSON unmarshaling function for Subject.
@@ -79,6 +376,14 @@ func (s *Subject) UnmarshalJSON(data []byte) error {
 	}
 
 	switch s.Object.ObjectType {
+	case ObjectTypeKanaVocabulary:
+		if _, ok := objMap["data"]; ok {
+			s.KanaVocabularyData = &SubjectKanaVocabularyData{}
+			if err := json.Unmarshal(objMap["data"], s.KanaVocabularyData); err != nil {
+				return fmt.Errorf("decoding kana vocabulary from subject: %w", err)
+			}
+		}
+
 	case ObjectTypeKanji:
 		if _, ok := objMap["data"]; ok {
 			s.KanjiData = &SubjectKanjiData{}
@@ -102,13 +407,21 @@ func (s *Subject) UnmarshalJSON(data []byte) error {
 				return fmt.Errorf("decoding vocabulary from subject: %w", err)
 			}
 		}
+
+	default:
+		// Keep data for subject types we don't know about so that it's not
+		// lost, and so that it can be decoded by the caller.
+		if data, ok := objMap["data"]; ok && string(data) != "null" {
+			s.UnknownData = data
+			s.unknownCommon = decodeSubjectUnknownCommonData(data)
+		}
 	}
 
 	return nil
 }
 
 // SubjectCommonData is common data available on all subject types regardless
-// of whether they're kanji, radical, or vocabulary.
+// of whether they're kanji, radical, vocabulary, or kana vocabulary.
 type SubjectCommonData struct {
 	AuxiliaryMeanings        []*SubjectAuxiliaryMeaningObject `json:"auxiliary_meanings"`
 	CreatedAt                time.Time                        `json:"created_at"`
@@ -116,11 +429,31 @@ type SubjectCommonData struct {
 	HiddenAt                 *time.Time                       `json:"hidden_at"`
 	Level                    int                              `json:"level"`
 	LessonPosition           int                              `json:"lesson_position"`
+	MeaningMnemonic          string                           `json:"meaning_mnemonic"`
 	Meanings                 []*SubjectMeaningObject          `json:"meanings"`
 	Slug                     string                           `json:"slug"`
 	SpacedRepetitionSystemID WKID                             `json:"spaced_repetition_system_id"`
 }
 
+// SubjectKanaVocabularyData is data on a kana vocabulary subject. Kana
+// vocabulary is written only in kana, so unlike regular vocabulary it has no
+// readings or component kanji.
+type SubjectKanaVocabularyData struct {
+	SubjectCommonData
+
+	Characters           string                                  `json:"characters"`
+	ContextSentences     []*SubjectVocabularyContextSentence     `json:"context_sentences"`
+	PartsOfSpeech        []string                                `json:"parts_of_speech"`
+	PronounciationAudios []*SubjectVocabularyPronounciationAudio `json:"pronunciation_audios"`
+}
+
+// BestPronounciationAudio returns the kana vocabulary's pronounciation audio
+// that best matches the given voice actor and content types. See
+// BestPronounciationAudio for details.
+func (d *SubjectKanaVocabularyData) BestPronounciationAudio(voiceActorID WKID, contentTypes ...string) *SubjectVocabularyPronounciationAudio {
+	return BestPronounciationAudio(d.PronounciationAudios, voiceActorID, contentTypes...)
+}
+
 // SubjectKanjiData is data on a kanji subject.
 type SubjectKanjiData struct {
 	SubjectCommonData
@@ -130,7 +463,7 @@ type SubjectKanjiData struct {
 	ComponentSubjectIDs       []WKID                 `json:"component_subject_ids"`
 	MeaningHint               *string                `json:"meaning_hint"`
 	ReadingHint               *string                `json:"reading_hint"`
-	ReadingMnemonic           string                 `json:"mnemonic_hint"`
+	ReadingMnemonic           string                 `json:"reading_mnemonic"`
 	Readings                  []*SubjectKanjiReading `json:"readings"`
 	VisuallySimilarSubjectIDs []WKID                 `json:"visually_similar_subject_ids"`
 }
@@ -158,9 +491,80 @@ const (
 type SubjectRadicalCharacterImage struct {
 	ContentType string                                                  `json:"content_type"`
 	Metadata    map[SubjectRadicalCharacterImageMetadataKey]interface{} `json:"metadata"`
-	URL         string                                                  `json:"url"`
+
+	// TypedMetadata is Metadata decoded into a struct. It's populated when
+	// the image is decoded from JSON, unless the metadata has a value of an
+	// unexpected type, in which case it's nil and only Metadata is set.
+	TypedMetadata *SubjectRadicalCharacterImageMetadata `json:"-"`
+
+	URL string `json:"url"`
 }
 
+// UnmarshalJSON is a custom JSON unmarshaling function for
+// SubjectRadicalCharacterImage that populates TypedMetadata in addition to
+// Metadata.
+func (i *SubjectRadicalCharacterImage) UnmarshalJSON(data []byte) error {
+	type image SubjectRadicalCharacterImage
+	var v image
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*i = SubjectRadicalCharacterImage(v)
+
+	var typed struct {
+		Metadata *SubjectRadicalCharacterImageMetadata `json:"metadata"`
+	}
+	// Metadata of an unexpected type leaves TypedMetadata nil rather than
+	// failing to decode the subject that it's part of. It's still
+	// available in Metadata.
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil
+	}
+	i.TypedMetadata = typed.Metadata
+
+	return nil
+}
+
+// SubjectRadicalCharacterImageMetadata is typed metadata for a radical
+// character image. Which fields are set depends on the image's content type.
+type SubjectRadicalCharacterImageMetadata struct {
+	// Color is the color of the image like `#000000`. Set for PNGs.
+	Color string `json:"color,omitempty"`
+
+	// Dimensions are the dimensions of the image like `64x64`. Set for PNGs.
+	Dimensions string `json:"dimensions,omitempty"`
+
+	// InlineStyles is whether the SVG has inline styles, or whether it
+	// expects to be styled by CSS. Set for SVGs.
+	InlineStyles *bool `json:"inline_styles,omitempty"`
+
+	// StyleName is the name of the image's style like `original` or `64px`.
+	// Set for PNGs.
+	StyleName string `json:"style_name,omitempty"`
+}
+
+// Size returns the width and height from Dimensions. Returns false if
+// dimensions weren't set or couldn't be parsed.
+func (m *SubjectRadicalCharacterImageMetadata) Size() (int, int, bool) {
+	var width, height int
+	if _, err := fmt.Sscanf(m.Dimensions, "%dx%d", &width, &height); err != nil {
+		return 0, 0, false
+	}
+
+	return width, height, true
+}
+
+// Content types of radical character images and vocabulary pronounciation
+// audio.
+const (
+	ContentTypeAudioMPEG = "audio/mpeg"
+	ContentTypeAudioOgg  = "audio/ogg"
+	ContentTypeAudioWebM = "audio/webm"
+	ContentTypeImagePNG  = "image/png"
+	ContentTypeImageSVG  = "image/svg+xml"
+)
+
 // SubjectRadicalCharacterImageMetadataKey is a key for character image metadata.
 type SubjectRadicalCharacterImageMetadataKey string
 
@@ -181,6 +585,68 @@ type SubjectRadicalData struct {
 	Characters             *string                         `json:"characters"`
 }
 
+// BestCharacterImagePNG returns the radical's PNG character image that's
+// closest in size to the given size in pixels, preferring the smallest image
+// that's at least as large so that it can be scaled down without losing
+// quality. Returns nil if the radical has no PNG images.
+func (d *SubjectRadicalData) BestCharacterImagePNG(size int) *SubjectRadicalCharacterImage {
+	var best *SubjectRadicalCharacterImage
+	var bestSize int
+
+	for _, image := range d.CharacterImages {
+		if image.ContentType != ContentTypeImagePNG {
+			continue
+		}
+
+		var imageSize int
+		if image.TypedMetadata != nil {
+			if width, height, ok := image.TypedMetadata.Size(); ok {
+				imageSize = width
+				if height > imageSize {
+					imageSize = height
+				}
+			}
+		}
+
+		if best == nil || betterImageSize(imageSize, bestSize, size) {
+			best = image
+			bestSize = imageSize
+		}
+	}
+
+	return best
+}
+
+// BestCharacterImageSVG returns the radical's SVG character image, preferring
+// one with or without inline styles as requested, but falling back to any
+// SVG. Returns nil if the radical has no SVG images.
+func (d *SubjectRadicalData) BestCharacterImageSVG(inlineStyles bool) *SubjectRadicalCharacterImage {
+	var fallback *SubjectRadicalCharacterImage
+
+	for _, image := range d.CharacterImages {
+		if image.ContentType != ContentTypeImageSVG {
+			continue
+		}
+
+		if image.TypedMetadata != nil && image.TypedMetadata.InlineStyles != nil &&
+			*image.TypedMetadata.InlineStyles == inlineStyles {
+			return image
+		}
+
+		if fallback == nil {
+			fallback = image
+		}
+	}
+
+	return fallback
+}
+
+// subjectWire is the shape of a Subject as it appears in WaniKani's API.
+type subjectWire struct {
+	Object
+	Data interface{} `json:"data,omitempty"`
+}
+
 // SubjectVocabularyContextSentence represents a vocabulary context sentence.
 type SubjectVocabularyContextSentence struct {
 	// EN is the English translation of the sentence.
@@ -197,10 +663,17 @@ type SubjectVocabularyData struct {
 	Characters           string                                  `json:"characters"`
 	ComponentSubjectIDs  []WKID                                  `json:"component_subject_ids"`
 	ContextSentences     []*SubjectVocabularyContextSentence     `json:"context_sentences"`
-	MeaningMnemonic      string                                  `json:"meaning_mnenomic"`
 	PartsOfSpeech        []string                                `json:"parts_of_speech"`
-	PronounciationAudios []*SubjectVocabularyPronounciationAudio `json:"pronounciation_audios"`
-	Readings             []*SubjectVocabularyReading             `json:"subject_vocabulary_reading"`
+	PronounciationAudios []*SubjectVocabularyPronounciationAudio `json:"pronunciation_audios"`
+	ReadingMnemonic      string                                  `json:"reading_mnemonic"`
+	Readings             []*SubjectVocabularyReading             `json:"readings"`
+}
+
+// BestPronounciationAudio returns the vocabulary's pronounciation audio that
+// best matches the given voice actor and content types. See
+// BestPronounciationAudio for details.
+func (d *SubjectVocabularyData) BestPronounciationAudio(voiceActorID WKID, contentTypes ...string) *SubjectVocabularyPronounciationAudio {
+	return BestPronounciationAudio(d.PronounciationAudios, voiceActorID, contentTypes...)
 }
 
 // SubjectVocabularyPronounciationAudio represets an audio object for
@@ -208,7 +681,61 @@ type SubjectVocabularyData struct {
 type SubjectVocabularyPronounciationAudio struct {
 	ContentType string                                                          `json:"content_type"`
 	Metadata    map[SubjectVocabularyPronounciationAudioMetadataKey]interface{} `json:"metadata"`
-	URL         string                                                          `json:"url"`
+
+	// TypedMetadata is Metadata decoded into a struct. It's populated when
+	// the audio is decoded from JSON, unless the metadata has a value of an
+	// unexpected type, in which case it's nil and only Metadata is set.
+	TypedMetadata *SubjectVocabularyPronounciationAudioMetadata `json:"-"`
+
+	URL string `json:"url"`
+}
+
+// UnmarshalJSON is a custom JSON unmarshaling function for
+// SubjectVocabularyPronounciationAudio that populates TypedMetadata in
+// addition to Metadata.
+func (a *SubjectVocabularyPronounciationAudio) UnmarshalJSON(data []byte) error {
+	type audio SubjectVocabularyPronounciationAudio
+	var v audio
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*a = SubjectVocabularyPronounciationAudio(v)
+
+	var typed struct {
+		Metadata *SubjectVocabularyPronounciationAudioMetadata `json:"metadata"`
+	}
+	// Metadata of an unexpected type leaves TypedMetadata nil rather than
+	// failing to decode the subject that it's part of. It's still
+	// available in Metadata.
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return nil
+	}
+	a.TypedMetadata = typed.Metadata
+
+	return nil
+}
+
+// SubjectVocabularyPronounciationAudioMetadata is typed metadata for
+// vocabulary pronounciation audio.
+type SubjectVocabularyPronounciationAudioMetadata struct {
+	// Gender is the gender of the voice actor like `male` or `female`.
+	Gender string `json:"gender"`
+
+	// Pronunciation is the vocabulary reading as spoken in the audio.
+	Pronunciation string `json:"pronunciation"`
+
+	// SourceID is a reference to the audio's source.
+	SourceID int64 `json:"source_id"`
+
+	// VoiceActorID is the ID of the VoiceActor in the audio.
+	VoiceActorID WKID `json:"voice_actor_id"`
+
+	// VoiceActorName is the name of the voice actor.
+	VoiceActorName string `json:"voice_actor_name"`
+
+	// VoiceDescription is a description of the voice like `Tokyo accent`.
+	VoiceDescription string `json:"voice_description"`
 }
 
 // SubjectVocabularyPronounciationAudioMetadataKey is a key for pronounciation
@@ -218,7 +745,7 @@ type SubjectVocabularyPronounciationAudioMetadataKey string
 // All possible values of vocabulary pronounciation audio metadata keys.
 const (
 	SubjectVocabularyPronounciationAudioMetadataKeyGender           SubjectVocabularyPronounciationAudioMetadataKey = "gender"
-	SubjectVocabularyPronounciationAudioMetadataKeyPronounciation   SubjectVocabularyPronounciationAudioMetadataKey = "pronounciation"
+	SubjectVocabularyPronounciationAudioMetadataKeyPronounciation   SubjectVocabularyPronounciationAudioMetadataKey = "pronunciation"
 	SubjectVocabularyPronounciationAudioMetadataKeySourceID         SubjectVocabularyPronounciationAudioMetadataKey = "source_id"
 	SubjectVocabularyPronounciationAudioMetadataKeyVoiceActorID     SubjectVocabularyPronounciationAudioMetadataKey = "voice_actor_id"
 	SubjectVocabularyPronounciationAudioMetadataKeyVoiceActorName   SubjectVocabularyPronounciationAudioMetadataKey = "voice_actor_name"
@@ -309,3 +836,86 @@ type SubjectPage struct {
 	PageObject
 	Data []*Subject `json:"data"`
 }
+
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p SubjectPage) MarshalJSON() ([]byte, error) {
+	type page SubjectPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Internal
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// betterImageSize returns true if an image of candidate size is a better fit
+// for target than one of current size. Images at least as large as target are
+// better than smaller ones, and closer sizes are better than farther ones.
+// Unknown sizes are represented as zero and are worst of all.
+func betterImageSize(candidate, current, target int) bool {
+	if candidate == 0 {
+		return false
+	}
+	if current == 0 {
+		return true
+	}
+
+	candidateFits := candidate >= target
+	currentFits := current >= target
+
+	switch {
+	case candidateFits && !currentFits:
+		return true
+	case !candidateFits && currentFits:
+		return false
+	case candidateFits:
+		return candidate < current
+	default:
+		return candidate > current
+	}
+}
+
+// contentTypeExtensions maps the content types of subject assets to file
+// extensions so that files saved to disk can be opened by programs that go by
+// extension.
+var contentTypeExtensions = map[string]string{
+	ContentTypeAudioMPEG: ".mp3",
+	ContentTypeAudioOgg:  ".ogg",
+	ContentTypeAudioWebM: ".webm",
+	ContentTypeImagePNG:  ".png",
+	ContentTypeImageSVG:  ".svg",
+}
+
+// subjectUnknownCommonData is data decoded from the UnknownData of a subject
+// whose type isn't known to the library.
+type subjectUnknownCommonData struct {
+	SubjectCommonData
+	Characters *string `json:"characters"`
+}
+
+func decodeSubjectUnknownCommonData(data json.RawMessage) *subjectUnknownCommonData {
+	common := &subjectUnknownCommonData{}
+	if err := json.Unmarshal(data, common); err != nil {
+		return nil
+	}
+	return common
+}
+
+// unknownCommonData returns data decoded from UnknownData. It's decoded once
+// when the subject is unmarshaled, but for a subject whose UnknownData was set
+// some other way, it's decoded on each call.
+func (s *Subject) unknownCommonData() *subjectUnknownCommonData {
+	if s.unknownCommon != nil {
+		return s.unknownCommon
+	}
+	return decodeSubjectUnknownCommonData(s.UnknownData)
+}
@@ -1,7 +1,11 @@
 package wanikaniapi_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"path/filepath"
 	"testing"
 
 	"github.com/brandur/wanikaniapi"
@@ -37,3 +41,379 @@ func TestSubjectGet(t *testing.T) {
 	assert.Equal(t, "/v2/subjects/123", req.Path)
 	assert.Equal(t, "", req.Query)
 }
+
+func TestSubjectListStream(t *testing.T) {
+	client := wktesting.LocalClient()
+
+	client.RecordedResponses = []*wanikaniapi.RecordedResponse{
+		{StatusCode: http.StatusOK, Body: []byte(`{
+			"object": "collection",
+			"pages": {
+				"per_page": 1000,
+				"next_url": "https://api.wanikani.com/v2/subjects?page_after_id=125",
+				"previous_url": null
+			},
+			"total_count": 3,
+			"data": [
+				{"id": 123, "object": "kanji", "data": {"characters": "一"}},
+				{"id": 124, "object": "radical", "data": {"characters": "二"}},
+				{"id": 125, "object": "vocabulary", "data": {"characters": "三"}}
+			]
+		}`)},
+	}
+
+	var subjects []*wanikaniapi.Subject
+	page, err := client.SubjectListStream(&wanikaniapi.SubjectListParams{
+		Levels: []int{1},
+	}, func(subject *wanikaniapi.Subject) error {
+		subjects = append(subjects, subject)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, wanikaniapi.ObjectTypeCollection, page.ObjectType)
+	assert.Equal(t, int64(3), page.TotalCount)
+	assert.Equal(t, 1000, page.Pages.PerPage)
+	assert.Equal(t, "https://api.wanikani.com/v2/subjects?page_after_id=125", page.Pages.NextURL)
+
+	assert.Equal(t, 3, len(subjects))
+	assert.Equal(t, "一", subjects[0].KanjiData.Characters)
+	assert.Equal(t, "二", *subjects[1].RadicalData.Characters)
+	assert.Equal(t, "三", subjects[2].VocabularyData.Characters)
+
+	req := client.RecordedRequests[0]
+	assert.Equal(t, http.MethodGet, req.Method)
+	assert.Equal(t, "/v2/subjects", req.Path)
+	assert.Equal(t, "levels=1", wktesting.MustQueryUnescape(req.Query))
+}
+
+func TestSubjectListTypes(t *testing.T) {
+	client := wktesting.LocalClient()
+
+	_, err := client.SubjectList(&wanikaniapi.SubjectListParams{
+		Types: []string{
+			string(wanikaniapi.ObjectTypeKanaVocabulary),
+			string(wanikaniapi.ObjectTypeVocabulary),
+		},
+	})
+	assert.NoError(t, err)
+
+	req := client.RecordedRequests[0]
+	assert.Equal(t, "types=kana_vocabulary,vocabulary", wktesting.MustQueryUnescape(req.Query))
+}
+
+func TestSubjectUnmarshalJSONKanaVocabulary(t *testing.T) {
+	var subject wanikaniapi.Subject
+	err := json.Unmarshal([]byte(`{
+		"id": 9210,
+		"object": "kana_vocabulary",
+		"data": {"characters": "おやつ", "level": 8}
+	}`), &subject)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "おやつ", subject.KanaVocabularyData.Characters)
+	assert.Equal(t, 8, subject.KanaVocabularyData.Level)
+	assert.Nil(t, subject.KanjiData)
+	assert.Nil(t, subject.RadicalData)
+	assert.Nil(t, subject.UnknownData)
+	assert.Nil(t, subject.VocabularyData)
+}
+
+func TestSubjectUnmarshalJSONUnknownType(t *testing.T) {
+	var subject wanikaniapi.Subject
+	err := json.Unmarshal([]byte(`{
+		"id": 9999,
+		"object": "future_type",
+		"data": {"characters": "未来"}
+	}`), &subject)
+	assert.NoError(t, err)
+
+	assert.Equal(t, wanikaniapi.WKObjectType("future_type"), subject.ObjectType)
+	assert.Equal(t, `{"characters": "未来"}`, string(subject.UnknownData))
+	assert.Nil(t, subject.KanaVocabularyData)
+	assert.Nil(t, subject.KanjiData)
+	assert.Nil(t, subject.RadicalData)
+	assert.Nil(t, subject.VocabularyData)
+
+	marshaled, err := json.Marshal(subject)
+	assert.NoError(t, err)
+	assert.Contains(t, string(marshaled), `"data":{"characters":"未来"}`)
+}
+
+func TestSubjectAccessors(t *testing.T) {
+	t.Run("Kanji", func(t *testing.T) {
+		subject := mustLoadSubject(t, "subject_kanji.json")
+		assert.Equal(t, wanikaniapi.ObjectTypeKanji, subject.Type())
+		assert.Equal(t, "一", subject.Characters())
+		assert.Equal(t, 1, subject.Level())
+		assert.Equal(t, "一", subject.Common().Slug)
+		assert.Equal(t, "One", subject.PrimaryMeaning())
+		assert.Equal(t, "いち", subject.PrimaryReading())
+		assert.Equal(t, []string{"One", "1"}, subject.AcceptedMeanings())
+		assert.Equal(t, []string{"いち"}, subject.AcceptedReadings())
+	})
+
+	t.Run("KanaVocabulary", func(t *testing.T) {
+		subject := mustLoadSubject(t, "subject_kana_vocabulary.json")
+		assert.Equal(t, wanikaniapi.ObjectTypeKanaVocabulary, subject.Type())
+		assert.Equal(t, "おやつ", subject.Characters())
+		assert.Equal(t, 8, subject.Level())
+		assert.Equal(t, "Snack", subject.PrimaryMeaning())
+		assert.Equal(t, "おやつ", subject.PrimaryReading())
+		assert.Equal(t, []string{"Snack"}, subject.AcceptedMeanings())
+		assert.Equal(t, []string{"おやつ"}, subject.AcceptedReadings())
+	})
+
+	t.Run("Radical", func(t *testing.T) {
+		subject := mustLoadSubject(t, "subject_radical.json")
+		assert.Equal(t, wanikaniapi.ObjectTypeRadical, subject.Type())
+		assert.Equal(t, "一", subject.Characters())
+		assert.Equal(t, "Ground", subject.PrimaryMeaning())
+		assert.Equal(t, "", subject.PrimaryReading())
+		assert.Equal(t, []string{"Ground"}, subject.AcceptedMeanings())
+		assert.Nil(t, subject.AcceptedReadings())
+	})
+
+	t.Run("RadicalImageOnly", func(t *testing.T) {
+		subject := mustLoadSubject(t, "subject_radical.json")
+		subject.RadicalData.Characters = nil
+		assert.Equal(t, "", subject.Characters())
+		assert.Equal(t, "Ground", subject.PrimaryMeaning())
+		assert.Equal(t, wanikaniapi.WKID(2), subject.Common().SpacedRepetitionSystemID)
+	})
+
+	t.Run("Vocabulary", func(t *testing.T) {
+		subject := mustLoadSubject(t, "subject_vocabulary.json")
+		assert.Equal(t, wanikaniapi.ObjectTypeVocabulary, subject.Type())
+		assert.Equal(t, "一", subject.Characters())
+		assert.Equal(t, "One", subject.PrimaryMeaning())
+		assert.Equal(t, "いち", subject.PrimaryReading())
+		assert.Equal(t, []string{"One", "1"}, subject.AcceptedMeanings())
+		assert.Equal(t, []string{"いち"}, subject.AcceptedReadings())
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		var subject wanikaniapi.Subject
+		assert.NoError(t, json.Unmarshal([]byte(`{
+			"id": 9999,
+			"object": "future_type",
+			"data": {
+				"characters": "未来",
+				"level": 3,
+				"meanings": [{"meaning": "Future", "primary": true, "accepted_answer": true}]
+			}
+		}`), &subject))
+		assert.Equal(t, wanikaniapi.WKObjectType("future_type"), subject.Type())
+		assert.Equal(t, "未来", subject.Characters())
+		assert.Equal(t, 3, subject.Level())
+		assert.Equal(t, "Future", subject.PrimaryMeaning())
+		assert.Equal(t, "", subject.PrimaryReading())
+
+		// Decoded once at unmarshal time rather than on every call.
+		assert.True(t, subject.Common() == subject.Common())
+
+		// Still decoded for a subject that wasn't unmarshaled.
+		literal := &wanikaniapi.Subject{
+			Object:      wanikaniapi.Object{ObjectType: "future_type"},
+			UnknownData: subject.UnknownData,
+		}
+		assert.Equal(t, "未来", literal.Characters())
+		assert.Equal(t, 3, literal.Level())
+	})
+
+	t.Run("NoData", func(t *testing.T) {
+		subject := &wanikaniapi.Subject{Object: wanikaniapi.Object{ObjectType: wanikaniapi.ObjectTypeKanji}}
+		assert.Nil(t, subject.Common())
+		assert.Equal(t, "", subject.Characters())
+		assert.Equal(t, 0, subject.Level())
+		assert.Equal(t, "", subject.PrimaryMeaning())
+		assert.Nil(t, subject.AcceptedMeanings())
+	})
+}
+
+func TestSubjectRadicalCharacterImageTypedMetadata(t *testing.T) {
+	subject := mustLoadSubject(t, "subject_radical.json")
+
+	svg := subject.RadicalData.CharacterImages[0]
+	assert.Equal(t, wanikaniapi.ContentTypeImageSVG, svg.ContentType)
+	assert.Equal(t, false, *svg.TypedMetadata.InlineStyles)
+	assert.Equal(t, false, svg.Metadata[wanikaniapi.SubjectRadicalCharacterImageMetadataKeyInlineStyles])
+
+	png := subject.RadicalData.CharacterImages[1]
+	assert.Equal(t, wanikaniapi.ContentTypeImagePNG, png.ContentType)
+	assert.Nil(t, png.TypedMetadata.InlineStyles)
+	assert.Equal(t, "#000000", png.TypedMetadata.Color)
+	assert.Equal(t, "original", png.TypedMetadata.StyleName)
+
+	width, height, ok := png.TypedMetadata.Size()
+	assert.True(t, ok)
+	assert.Equal(t, 64, width)
+	assert.Equal(t, 64, height)
+
+	_, _, ok = svg.TypedMetadata.Size()
+	assert.False(t, ok)
+}
+
+func TestSubjectTypedMetadataUnexpectedType(t *testing.T) {
+	var subjects []*wanikaniapi.Subject
+	assert.NoError(t, json.Unmarshal([]byte(`[
+		{"id": 1, "object": "radical", "data": {"character_images": [
+			{"content_type": "image/png", "metadata": {"color": 0, "dimensions": "64x64"}, "url": "https://example.com/1.png"}
+		]}},
+		{"id": 2, "object": "vocabulary", "data": {"pronunciation_audios": [
+			{"content_type": "audio/webm", "metadata": {"voice_actor_id": "two"}, "url": "https://example.com/2.webm"}
+		]}}
+	]`), &subjects))
+
+	image := subjects[0].RadicalData.CharacterImages[0]
+	assert.Nil(t, image.TypedMetadata)
+	assert.Equal(t, float64(0), image.Metadata[wanikaniapi.SubjectRadicalCharacterImageMetadataKeyColor])
+	assert.Equal(t, "https://example.com/1.png", image.URL)
+
+	audio := subjects[1].VocabularyData.PronounciationAudios[0]
+	assert.Nil(t, audio.TypedMetadata)
+	assert.Equal(t, "two", audio.Metadata[wanikaniapi.SubjectVocabularyPronounciationAudioMetadataKeyVoiceActorID])
+	assert.Equal(t, "https://example.com/2.webm", audio.URL)
+}
+
+func TestSubjectRadicalDataBestCharacterImage(t *testing.T) {
+	image := func(contentType string, metadata string) *wanikaniapi.SubjectRadicalCharacterImage {
+		image := &wanikaniapi.SubjectRadicalCharacterImage{}
+		assert.NoError(t, json.Unmarshal([]byte(`{
+			"content_type": "`+contentType+`",
+			"metadata": `+metadata+`,
+			"url": "https://example.com"
+		}`), image))
+		return image
+	}
+
+	svgPlain := image(wanikaniapi.ContentTypeImageSVG, `{"inline_styles": false}`)
+	svgInline := image(wanikaniapi.ContentTypeImageSVG, `{"inline_styles": true}`)
+	png32 := image(wanikaniapi.ContentTypeImagePNG, `{"dimensions": "32x32"}`)
+	png64 := image(wanikaniapi.ContentTypeImagePNG, `{"dimensions": "64x64"}`)
+	png256 := image(wanikaniapi.ContentTypeImagePNG, `{"dimensions": "256x256"}`)
+
+	data := &wanikaniapi.SubjectRadicalData{
+		CharacterImages: []*wanikaniapi.SubjectRadicalCharacterImage{
+			png256, svgPlain, png32, svgInline, png64,
+		},
+	}
+
+	assert.Equal(t, svgInline, data.BestCharacterImageSVG(true))
+	assert.Equal(t, svgPlain, data.BestCharacterImageSVG(false))
+
+	assert.Equal(t, png64, data.BestCharacterImagePNG(64))
+	assert.Equal(t, png64, data.BestCharacterImagePNG(50))
+	assert.Equal(t, png32, data.BestCharacterImagePNG(16))
+	assert.Equal(t, png256, data.BestCharacterImagePNG(100))
+	assert.Equal(t, png256, data.BestCharacterImagePNG(1024))
+
+	// Falls back to any SVG.
+	data = &wanikaniapi.SubjectRadicalData{
+		CharacterImages: []*wanikaniapi.SubjectRadicalCharacterImage{png32, svgPlain},
+	}
+	assert.Equal(t, svgPlain, data.BestCharacterImageSVG(true))
+
+	// Nothing to choose from.
+	data = &wanikaniapi.SubjectRadicalData{}
+	assert.Nil(t, data.BestCharacterImageSVG(true))
+	assert.Nil(t, data.BestCharacterImagePNG(64))
+}
+
+func TestBestPronounciationAudio(t *testing.T) {
+	audio := func(contentType string, voiceActorID int) *wanikaniapi.SubjectVocabularyPronounciationAudio {
+		audio := &wanikaniapi.SubjectVocabularyPronounciationAudio{}
+		assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{
+			"content_type": %q,
+			"metadata": {"voice_actor_id": %v, "gender": "female"},
+			"url": "https://example.com"
+		}`, contentType, voiceActorID)), audio))
+		return audio
+	}
+
+	mpeg1 := audio(wanikaniapi.ContentTypeAudioMPEG, 1)
+	ogg1 := audio(wanikaniapi.ContentTypeAudioOgg, 1)
+	mpeg2 := audio(wanikaniapi.ContentTypeAudioMPEG, 2)
+	webm2 := audio(wanikaniapi.ContentTypeAudioWebM, 2)
+
+	audios := []*wanikaniapi.SubjectVocabularyPronounciationAudio{mpeg1, ogg1, mpeg2, webm2}
+
+	assert.Equal(t, wanikaniapi.WKID(2), webm2.TypedMetadata.VoiceActorID)
+	assert.Equal(t, "female", webm2.TypedMetadata.Gender)
+
+	assert.Equal(t, webm2, wanikaniapi.BestPronounciationAudio(audios, 2,
+		wanikaniapi.ContentTypeAudioWebM, wanikaniapi.ContentTypeAudioMPEG))
+	assert.Equal(t, ogg1, wanikaniapi.BestPronounciationAudio(audios, 1,
+		wanikaniapi.ContentTypeAudioWebM, wanikaniapi.ContentTypeAudioOgg))
+
+	// No preferred content type available for the voice actor, so another
+	// voice actor's audio in that content type.
+	assert.Equal(t, webm2, wanikaniapi.BestPronounciationAudio(audios, 1, wanikaniapi.ContentTypeAudioWebM))
+
+	// No preferred content type available at all, so the first of the voice
+	// actor's audios.
+	assert.Equal(t, mpeg2, wanikaniapi.BestPronounciationAudio(audios, 2, "audio/wav"))
+
+	// Unknown voice actor falls back to any voice actor.
+	assert.Equal(t, webm2, wanikaniapi.BestPronounciationAudio(audios, 99, wanikaniapi.ContentTypeAudioWebM))
+
+	// Zero voice actor matches anyone.
+	assert.Equal(t, mpeg1, wanikaniapi.BestPronounciationAudio(audios, 0, wanikaniapi.ContentTypeAudioMPEG))
+
+	assert.Nil(t, wanikaniapi.BestPronounciationAudio(nil, 1, wanikaniapi.ContentTypeAudioMPEG))
+
+	subject := mustLoadSubject(t, "subject_vocabulary.json")
+	best := subject.VocabularyData.BestPronounciationAudio(2, wanikaniapi.ContentTypeAudioOgg)
+	assert.Equal(t, wanikaniapi.ContentTypeAudioOgg, best.ContentType)
+	assert.Equal(t, "いち", best.TypedMetadata.Pronunciation)
+	assert.Equal(t, int64(2711), best.TypedMetadata.SourceID)
+	assert.Equal(t, "Kenichi", best.TypedMetadata.VoiceActorName)
+}
+
+func TestSubjectPronounciationAudioForUser(t *testing.T) {
+	user := &wanikaniapi.User{Data: &wanikaniapi.UserData{
+		Preferences: &wanikaniapi.UserPreferences{DefaultVoiceActorID: 2},
+	}}
+
+	subject := mustLoadSubject(t, "subject_vocabulary.json")
+
+	audio := subject.PronounciationAudioForUser(user,
+		wanikaniapi.ContentTypeAudioWebM, wanikaniapi.ContentTypeAudioOgg, wanikaniapi.ContentTypeAudioMPEG)
+	assert.Equal(t, wanikaniapi.ContentTypeAudioOgg, audio.ContentType)
+	assert.Equal(t, wanikaniapi.WKID(2), audio.TypedMetadata.VoiceActorID)
+
+	// A voice actor without audio and no user at all both fall back to
+	// whatever's available.
+	user.Data.Preferences.DefaultVoiceActorID = 99
+	audio = subject.PronounciationAudioForUser(user, wanikaniapi.ContentTypeAudioMPEG)
+	assert.Equal(t, wanikaniapi.ContentTypeAudioMPEG, audio.ContentType)
+
+	audio = subject.PronounciationAudioForUser(nil, wanikaniapi.ContentTypeAudioOgg)
+	assert.Equal(t, wanikaniapi.ContentTypeAudioOgg, audio.ContentType)
+
+	kanaVocabulary := mustLoadSubject(t, "subject_kana_vocabulary.json")
+	assert.NotNil(t, kanaVocabulary.PronounciationAudioForUser(user))
+
+	kanji := mustLoadSubject(t, "subject_kanji.json")
+	assert.Nil(t, kanji.PronounciationAudioForUser(user, wanikaniapi.ContentTypeAudioMPEG))
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//
+//
+//
+// Private
+//
+//
+//
+//////////////////////////////////////////////////////////////////////////////
+
+// mustLoadSubject decodes a subject from a fixture in `testdata/`.
+func mustLoadSubject(t *testing.T, fixture string) *wanikaniapi.Subject {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+
+	subject := &wanikaniapi.Subject{}
+	assert.NoError(t, json.Unmarshal(data, subject))
+	return subject
+}
@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -38,6 +39,16 @@ type Summary struct {
 	Data *SummaryData `json:"data"`
 }
 
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for the summary.
+func (s Summary) MarshalJSON() ([]byte, error) {
+	type summary Summary
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		summary
+	}{idOrNil(s.ID), summary(s)})
+}
+
 // SummaryData contains core data of Summary.
 type SummaryData struct {
 	Lessons       []*SummaryLesson `json:"lessons"`
@@ -62,6 +73,16 @@ type SummaryPage struct {
 	Data []*Summary `json:"data"`
 }
 
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p SummaryPage) MarshalJSON() ([]byte, error) {
+	type page SummaryPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}
+
 // SummaryReview provides a summary about reviews.
 type SummaryReview struct {
 	AvailableAt time.Time `json:"available_at"`
@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -46,6 +47,16 @@ type User struct {
 	Data *UserData `json:"data"`
 }
 
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for the user.
+func (u User) MarshalJSON() ([]byte, error) {
+	type user User
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		user
+	}{idOrNil(u.ID), user(u)})
+}
+
 // UserData contains core data of User.
 type UserData struct {
 	CurrentVacationStartedAt *time.Time        `json:"current_vacation_started_at"`
@@ -66,11 +77,13 @@ type UserGetParams struct {
 // UserPreferences are preferences for a user.
 type UserPreferences struct {
 	DefaultVoiceActorID        WKID   `json:"default_voice_actor_id"`
+	ExtraStudyAutoplayAudio    bool   `json:"extra_study_autoplay_audio"`
 	LessonsAutoplayAudio       bool   `json:"lessons_autoplay_audio"`
 	LessonsBatchSize           int    `json:"lessons_batch_size"`
 	LessonsPresentationOrder   string `json:"lessons_presentation_order"`
 	ReviewsAutoplayAudio       bool   `json:"reviews_autoplay_audio"`
 	ReviewsDisplaySRSIndicator bool   `json:"reviews_display_srs_indicator"`
+	ReviewsPresentationOrder   string `json:"reviews_presentation_order"`
 }
 
 // UserSubscription represents a subscription for a user.
@@ -1,6 +1,7 @@
 package wanikaniapi
 
 import (
+	"encoding/json"
 	"strconv"
 )
 
@@ -88,3 +89,13 @@ type VoiceActorPage struct {
 	PageObject
 	Data []*VoiceActor `json:"data"`
 }
+
+// MarshalJSON leaves out `id` when it's zero because WaniKani doesn't send
+// one for collections.
+func (p VoiceActorPage) MarshalJSON() ([]byte, error) {
+	type page VoiceActorPage
+	return json.Marshal(&struct {
+		ID *WKID `json:"id,omitempty"`
+		page
+	}{idOrNil(p.ID), page(p)})
+}